@@ -0,0 +1,332 @@
+/*
+Package arith performs arithmetic on primitive values of any numeric type, using rprim to convert the
+operands and the results.
+
+The operands are promoted to a common type using these rules:
+
+  - operands of the same type keep it, including named types
+  - if any operand is complex the result is complex, else if any is a float the result is a float;
+    float32 (or complex64) is used only if all operands fit in it exactly
+  - signed integers are widened to the widest operand, as are unsigned integers
+  - mixed signed and unsigned integers are widened to a signed integer able to hold both, up to int64;
+    with a 64-bit unsigned operand, results too large for int64 are uint64
+  - numeric strings are parsed as int64 if integral, else as float64, ignoring the rprim.Config options
+
+Integer operations are computed exactly, and the result is converted to the result type using the
+overflow mode of the rprim.Config.
+
+	v, err := arith.Add(int8(100), uint16(3)) // int32(103)
+*/
+package arith
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+	"reflect"
+
+	"github.com/RangelReale/rprim"
+)
+
+// The divisor is zero
+var ErrDivisionByZero = errors.New("Division by zero")
+
+// Performs arithmetic operations, converting the operands and results using the Config.
+type Arith struct {
+	// Config used to convert the numeric operands and the results. Its Overflow mode applies to integer results.
+	Config *rprim.Config
+	// Type of the results, or nil to use the type promoted from the operands.
+	// The operation is computed in the type promoted from the operands and the result type, so dividing
+	// integers with a float result type is a float division.
+	Result reflect.Type
+}
+
+// Creates a new arithmetic evaluator. If config is nil, a default one using rprim.OVERFLOW_ERROR is used.
+func New(config *rprim.Config) *Arith {
+	if config == nil {
+		config = rprim.NewConfig().SetOverflow(rprim.OVERFLOW_ERROR)
+	}
+	return &Arith{
+		Config: config,
+	}
+}
+
+// Returns x + y.
+func (a *Arith) Add(x, y interface{}) (interface{}, error) {
+	return a.binary("add", x, y)
+}
+
+// Returns x - y.
+func (a *Arith) Sub(x, y interface{}) (interface{}, error) {
+	return a.binary("sub", x, y)
+}
+
+// Returns x * y.
+func (a *Arith) Mul(x, y interface{}) (interface{}, error) {
+	return a.binary("mul", x, y)
+}
+
+// Returns x / y. Integer division truncates toward zero.
+// Returns ErrDivisionByZero if y is zero, also for floats.
+func (a *Arith) Div(x, y interface{}) (interface{}, error) {
+	return a.binary("div", x, y)
+}
+
+// Returns the remainder of x / y, with the sign of x. Complex numbers are not supported.
+// Returns ErrDivisionByZero if y is zero, also for floats.
+func (a *Arith) Mod(x, y interface{}) (interface{}, error) {
+	return a.binary("mod", x, y)
+}
+
+// Returns -x, in the type of x unless a result type is set.
+func (a *Arith) Neg(x interface{}) (interface{}, error) {
+	ox, err := newOperand(x)
+	if err != nil {
+		return nil, err
+	}
+	t := a.resultType(ox.typ)
+	d, err := a.toDomain(ox, computeType(ox.typ, t))
+	if err != nil {
+		return nil, err
+	}
+	switch dv := d.(type) {
+	case *big.Int:
+		return a.result(new(big.Int).Neg(dv), t)
+	case float64:
+		return a.result(-dv, t)
+	case complex128:
+		return a.result(-dv, t)
+	}
+	return nil, fmt.Errorf("Invalid operand %v", x)
+}
+
+// Returns the absolute value of x, in the type of x unless a result type is set.
+// The absolute value of complex numbers is a float of the same width.
+func (a *Arith) Abs(x interface{}) (interface{}, error) {
+	ox, err := newOperand(x)
+	if err != nil {
+		return nil, err
+	}
+	t := ox.typ
+	switch ox.typ.Kind() {
+	case reflect.Complex64:
+		t = typeFloat32
+	case reflect.Complex128:
+		t = typeFloat64
+	}
+	t = a.resultType(t)
+	d, err := a.toDomain(ox, computeType(ox.typ, t))
+	if err != nil {
+		return nil, err
+	}
+	switch dv := d.(type) {
+	case *big.Int:
+		return a.result(new(big.Int).Abs(dv), t)
+	case float64:
+		return a.result(math.Abs(dv), t)
+	case complex128:
+		return a.result(cmplx.Abs(dv), t)
+	}
+	return nil, fmt.Errorf("Invalid operand %v", x)
+}
+
+func (a *Arith) binary(op string, x, y interface{}) (interface{}, error) {
+	ox, err := newOperand(x)
+	if err != nil {
+		return nil, err
+	}
+	oy, err := newOperand(y)
+	if err != nil {
+		return nil, err
+	}
+	promoted := promoteTypes(ox.typ, oy.typ)
+	t := a.resultType(promoted)
+	domain := computeType(promoted, t)
+
+	dx, err := a.toDomain(ox, domain)
+	if err != nil {
+		return nil, err
+	}
+	dy, err := a.toDomain(oy, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	switch vx := dx.(type) {
+	case *big.Int:
+		vy := dy.(*big.Int)
+		r := new(big.Int)
+		switch op {
+		case "add":
+			r.Add(vx, vy)
+		case "sub":
+			r.Sub(vx, vy)
+		case "mul":
+			r.Mul(vx, vy)
+		case "div", "mod":
+			if vy.Sign() == 0 {
+				return nil, ErrDivisionByZero
+			}
+			if op == "div" {
+				r.Quo(vx, vy)
+			} else {
+				r.Rem(vx, vy)
+			}
+		}
+		if a.Result == nil && !r.IsInt64() && r.IsUint64() && isMixedUnsigned64(ox.typ, oy.typ) {
+			t = typeUint64
+		}
+		return a.result(r, t)
+	case float64:
+		vy := dy.(float64)
+		var r float64
+		switch op {
+		case "add":
+			r = vx + vy
+		case "sub":
+			r = vx - vy
+		case "mul":
+			r = vx * vy
+		case "div", "mod":
+			if vy == 0 {
+				return nil, ErrDivisionByZero
+			}
+			if op == "div" {
+				r = vx / vy
+			} else {
+				r = math.Mod(vx, vy)
+			}
+		}
+		return a.result(r, t)
+	case complex128:
+		vy := dy.(complex128)
+		var r complex128
+		switch op {
+		case "add":
+			r = vx + vy
+		case "sub":
+			r = vx - vy
+		case "mul":
+			r = vx * vy
+		case "div":
+			if vy == 0 {
+				return nil, ErrDivisionByZero
+			}
+			r = vx / vy
+		case "mod":
+			return nil, fmt.Errorf("Modulo is not supported for %s", domain.String())
+		}
+		return a.result(r, t)
+	}
+	return nil, fmt.Errorf("Invalid operands %v and %v", x, y)
+}
+
+func (a *Arith) resultType(promoted reflect.Type) reflect.Type {
+	if a.Result != nil {
+		return a.Result
+	}
+	return promoted
+}
+
+// Returns the type the operation is computed in. Non-numeric result types, like string, only convert
+// the result.
+func computeType(promoted, result reflect.Type) reflect.Type {
+	if !kindIsNumeric(rprim.UnderliningTypeKind(result)) {
+		return promoted
+	}
+	return promoteTypes(promoted, rprim.UnderliningType(result))
+}
+
+// Returns the operand in the computation domain of the type: *big.Int for integers, float64 for floats
+// and complex128 for complex numbers.
+func (a *Arith) toDomain(o operand, domain reflect.Type) (interface{}, error) {
+	switch {
+	case kindIsComplex(domain.Kind()):
+		if kindIsComplex(o.typ.Kind()) {
+			return o.value.Complex(), nil
+		}
+		f, err := a.Config.Convert(o.value, typeFloat64)
+		if err != nil {
+			return nil, err
+		}
+		return complex(f.Float(), 0), nil
+	case kindIsFloat(domain.Kind()):
+		f, err := a.Config.Convert(o.value, typeFloat64)
+		if err != nil {
+			return nil, err
+		}
+		return f.Float(), nil
+	case kindIsSigned(o.typ.Kind()):
+		return big.NewInt(o.value.Int()), nil
+	case kindIsUnsigned(o.typ.Kind()):
+		return new(big.Int).SetUint64(o.value.Uint()), nil
+	}
+	return nil, fmt.Errorf("Cannot compute %s in %s", o.typ.String(), domain.String())
+}
+
+// Converts the result of the computation to the type using the Config.
+func (a *Arith) result(r interface{}, t reflect.Type) (interface{}, error) {
+	rv := reflect.ValueOf(r)
+	switch rt := r.(type) {
+	case *big.Int:
+		switch {
+		case rt.IsInt64():
+			rv = reflect.ValueOf(rt.Int64())
+		case rt.IsUint64():
+			rv = reflect.ValueOf(rt.Uint64())
+		case a.Config.Overflow == rprim.OVERFLOW_WRAP && (kindIsSigned(t.Kind()) || kindIsUnsigned(t.Kind())):
+			// big numbers are always range checked, keep the low 64 bits
+			low := new(big.Int).And(rt, new(big.Int).SetUint64(math.MaxUint64))
+			rv = reflect.ValueOf(low.Uint64())
+		}
+	case complex128:
+		if !kindIsComplex(t.Kind()) {
+			if imag(rt) != 0 {
+				return nil, fmt.Errorf("Error converting %v to %s: imaginary part is not zero", rt, t.String())
+			}
+			rv = reflect.ValueOf(real(rt))
+		}
+	}
+	cv, err := a.Config.Convert(rv, t)
+	if err != nil {
+		return nil, err
+	}
+	return cv.Interface(), nil
+}
+
+// Helper to return x + y.
+func Add(x, y interface{}) (interface{}, error) {
+	return New(nil).Add(x, y)
+}
+
+// Helper to return x - y.
+func Sub(x, y interface{}) (interface{}, error) {
+	return New(nil).Sub(x, y)
+}
+
+// Helper to return x * y.
+func Mul(x, y interface{}) (interface{}, error) {
+	return New(nil).Mul(x, y)
+}
+
+// Helper to return x / y.
+func Div(x, y interface{}) (interface{}, error) {
+	return New(nil).Div(x, y)
+}
+
+// Helper to return the remainder of x / y.
+func Mod(x, y interface{}) (interface{}, error) {
+	return New(nil).Mod(x, y)
+}
+
+// Helper to return -x.
+func Neg(x interface{}) (interface{}, error) {
+	return New(nil).Neg(x)
+}
+
+// Helper to return the absolute value of x.
+func Abs(x interface{}) (interface{}, error) {
+	return New(nil).Abs(x)
+}
@@ -0,0 +1,169 @@
+package arith
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/RangelReale/rprim"
+)
+
+type testMoney int64
+
+func TestPromotion(t *testing.T) {
+	i := 4
+	tests := []struct {
+		x, y     interface{}
+		expected interface{}
+	}{
+		{1, 2, 3},
+		{int8(100), uint16(3), int32(103)},
+		{int8(1), int32(2), int32(3)},
+		{uint8(1), uint32(2), uint32(3)},
+		{int64(1), uint64(2), int64(3)},
+		{int16(1), float32(0.5), float32(1.5)},
+		{int32(1), float32(0.5), float64(1.5)},
+		{float32(1), 0.5, float64(1.5)},
+		{float32(1), complex64(2i), complex64(1 + 2i)},
+		{1, complex64(2i), complex128(1 + 2i)},
+		{&i, "2", int64(6)},
+		{"1.5", 1, float64(2.5)},
+		{testMoney(5), testMoney(6), testMoney(11)},
+		{testMoney(5), 6, int64(11)},
+		{uint64(math.MaxUint64), int8(0), uint64(math.MaxUint64)},
+		{uint64(math.MaxUint64 - 1), int64(1), uint64(math.MaxUint64)},
+	}
+	for _, test := range tests {
+		v, err := Add(test.x, test.y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != test.expected {
+			t.Fatalf("%#v + %#v expected %#v, got %#v", test.x, test.y, test.expected, v)
+		}
+	}
+}
+
+func TestOperations(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func(x, y interface{}) (interface{}, error)
+		x, y     interface{}
+		expected interface{}
+	}{
+		{"sub", Sub, uint8(5), int8(7), int16(-2)},
+		{"sub", Sub, uint64(1), int8(5), int64(-4)},
+		{"mul", Mul, int16(300), int16(100), int16(30000)},
+		{"div", Div, -7, 2, -3},
+		{"div", Div, 7.0, 2, 3.5},
+		{"div", Div, complex(4, 2), complex(2, 1), complex(2, 0)},
+		{"mod", Mod, -7, 2, -1},
+		{"mod", Mod, 7.5, 2, 1.5},
+	}
+	for _, test := range tests {
+		v, err := test.fn(test.x, test.y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != test.expected {
+			t.Fatalf("%s %#v %#v expected %#v, got %#v", test.name, test.x, test.y, test.expected, v)
+		}
+	}
+
+	if v, err := Neg(int8(5)); err != nil || v != int8(-5) {
+		t.Fatalf("Neg expected -5, got %#v (%v)", v, err)
+	}
+	if v, err := Abs(-2.5); err != nil || v != 2.5 {
+		t.Fatalf("Abs expected 2.5, got %#v (%v)", v, err)
+	}
+	if v, err := Abs(complex64(3 + 4i)); err != nil || v != float32(5) {
+		t.Fatalf("Abs expected float32 5, got %#v (%v)", v, err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, y := range []interface{}{0, 0.0, complex(0, 0)} {
+		if _, err := Div(1, y); !errors.Is(err, ErrDivisionByZero) {
+			t.Fatalf("Expected division by zero error, got %v", err)
+		}
+	}
+	if _, err := Mod(1, 0); !errors.Is(err, ErrDivisionByZero) {
+		t.Fatalf("Expected division by zero error, got %v", err)
+	}
+	if _, err := Mod(complex(1, 1), 2); err == nil {
+		t.Fatal("Expected error for complex modulo")
+	}
+	for _, x := range []interface{}{nil, (*int)(nil), "x", true, []int{1}} {
+		if _, err := Add(x, 1); err == nil {
+			t.Fatalf("Expected error for operand %#v", x)
+		}
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	overflows := []func() (interface{}, error){
+		func() (interface{}, error) { return Add(int8(100), int8(100)) },
+		func() (interface{}, error) { return Mul(int64(math.MaxInt64), int64(2)) },
+		func() (interface{}, error) { return Sub(uint8(1), uint8(2)) },
+		func() (interface{}, error) { return Div(int64(math.MinInt64), int64(-1)) },
+		func() (interface{}, error) { return Neg(uint(1)) },
+		func() (interface{}, error) { return Abs(int8(math.MinInt8)) },
+	}
+	for i, fn := range overflows {
+		if _, err := fn(); !errors.Is(err, rprim.ErrOverflow) {
+			t.Fatalf("Test %d expected overflow error, got %v", i, err)
+		}
+	}
+
+	wrap := New(rprim.NewConfig())
+	if v, err := wrap.Add(int8(100), int8(100)); err != nil || v != int8(-56) {
+		t.Fatalf("Expected -56, got %#v (%v)", v, err)
+	}
+	if v, err := wrap.Mul(int64(math.MaxInt64), int64(4)); err != nil || v != int64(-4) {
+		t.Fatalf("Expected -4, got %#v (%v)", v, err)
+	}
+
+	saturate := New(rprim.NewConfig().SetOverflow(rprim.OVERFLOW_SATURATE))
+	if v, err := saturate.Add(int8(100), int8(100)); err != nil || v != int8(127) {
+		t.Fatalf("Expected 127, got %#v (%v)", v, err)
+	}
+}
+
+func TestStringOperands(t *testing.T) {
+	lenient := New(rprim.NewConfig().AddFlags(rprim.COP_LENIENT_NUMERIC_STRING))
+	if v, err := lenient.Add("2.5", 1); err != nil || v != 3.5 {
+		t.Fatalf("Expected 3.5, got %#v (%v)", v, err)
+	}
+
+	scaled := New(rprim.NewConfig().SetScale(2))
+	if v, err := scaled.Add("2", 1); err != nil || v != int64(3) {
+		t.Fatalf("Expected 3, got %#v (%v)", v, err)
+	}
+}
+
+func TestResultType(t *testing.T) {
+	a := New(nil)
+	a.Result = reflect.TypeOf(float64(0))
+	if v, err := a.Div(7, 2); err != nil || v != 3.5 {
+		t.Fatalf("Expected 3.5, got %#v (%v)", v, err)
+	}
+
+	a.Result = reflect.TypeOf(int8(0))
+	if v, err := a.Add(int64(100), int64(27)); err != nil || v != int8(127) {
+		t.Fatalf("Expected 127, got %#v (%v)", v, err)
+	}
+	if _, err := a.Add(int64(100), int64(28)); !errors.Is(err, rprim.ErrOverflow) {
+		t.Fatalf("Expected overflow error, got %v", err)
+	}
+
+	a.Result = reflect.TypeOf("")
+	if v, err := a.Add(1, 2); err != nil || v != "3" {
+		t.Fatalf("Expected \"3\", got %#v (%v)", v, err)
+	}
+
+	a.Result = reflect.TypeOf(float64(0))
+	if _, err := a.Add(complex(1, 1), 1); err == nil {
+		t.Fatal("Expected error converting complex with imaginary part to float")
+	}
+}
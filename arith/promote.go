@@ -0,0 +1,167 @@
+package arith
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/RangelReale/rprim"
+)
+
+var (
+	typeInt64      = reflect.TypeOf(int64(0))
+	typeUint64     = reflect.TypeOf(uint64(0))
+	typeFloat32    = reflect.TypeOf(float32(0))
+	typeFloat64    = reflect.TypeOf(float64(0))
+	typeComplex64  = reflect.TypeOf(complex64(0))
+	typeComplex128 = reflect.TypeOf(complex128(0))
+
+	signedTypes = map[int]reflect.Type{
+		8:  reflect.TypeOf(int8(0)),
+		16: reflect.TypeOf(int16(0)),
+		32: reflect.TypeOf(int32(0)),
+		64: typeInt64,
+	}
+	unsignedTypes = map[int]reflect.Type{
+		8:  reflect.TypeOf(uint8(0)),
+		16: reflect.TypeOf(uint16(0)),
+		32: reflect.TypeOf(uint32(0)),
+		64: typeUint64,
+	}
+)
+
+// A numeric operand, after pointer dereferences.
+type operand struct {
+	typ   reflect.Type
+	value reflect.Value
+}
+
+// Returns the value as a numeric operand. Numeric strings are parsed as int64 if integral, else as float64,
+// without the Config options, which only apply to the conversion of numbers.
+func newOperand(v interface{}) (operand, error) {
+	if v == nil {
+		return operand{}, fmt.Errorf("Invalid operand: nil")
+	}
+	uv, err := rprim.SafeUnderliningValue(reflect.ValueOf(v), 0)
+	if err != nil {
+		return operand{}, err
+	}
+	if (uv.Kind() == reflect.Ptr || uv.Kind() == reflect.Interface) && uv.IsNil() {
+		return operand{}, fmt.Errorf("Invalid operand: nil %s", uv.Type().String())
+	}
+
+	switch {
+	case kindIsSigned(uv.Kind()), kindIsUnsigned(uv.Kind()), kindIsFloat(uv.Kind()), kindIsComplex(uv.Kind()):
+		return operand{typ: uv.Type(), value: uv}, nil
+	case uv.Kind() == reflect.String:
+		if i, err := strconv.ParseInt(uv.String(), 10, 64); err == nil {
+			return operand{typ: typeInt64, value: reflect.ValueOf(i)}, nil
+		}
+		if f, err := strconv.ParseFloat(uv.String(), 64); err == nil {
+			return operand{typ: typeFloat64, value: reflect.ValueOf(f)}, nil
+		}
+		return operand{}, fmt.Errorf("Invalid operand: '%s' is not a number", uv.String())
+	}
+	return operand{}, fmt.Errorf("Invalid operand of type %s", uv.Type().String())
+}
+
+// Returns the type of the result of an operation between values of the types.
+// Types must be numeric.
+func promoteTypes(x, y reflect.Type) reflect.Type {
+	if x == y {
+		return x
+	}
+	xk, yk := x.Kind(), y.Kind()
+
+	switch {
+	case kindIsComplex(xk) || kindIsComplex(yk):
+		if floatBits(x) <= 32 && floatBits(y) <= 32 {
+			return typeComplex64
+		}
+		return typeComplex128
+	case kindIsFloat(xk) || kindIsFloat(yk):
+		if floatBits(x) <= 32 && floatBits(y) <= 32 {
+			return typeFloat32
+		}
+		return typeFloat64
+	case kindIsSigned(xk) && kindIsSigned(yk):
+		return signedTypes[maxInt(x.Bits(), y.Bits())]
+	case kindIsUnsigned(xk) && kindIsUnsigned(yk):
+		return unsignedTypes[maxInt(x.Bits(), y.Bits())]
+	}
+
+	// mixed signed and unsigned, the signed type must hold all unsigned values
+	signed, unsigned := x, y
+	if kindIsUnsigned(xk) {
+		signed, unsigned = y, x
+	}
+	return signedTypes[minInt(maxInt(signed.Bits(), unsigned.Bits()*2), 64)]
+}
+
+// Checks if the types are a signed integer and a 64-bit unsigned integer, which are promoted to int64 even
+// though it cannot hold all the unsigned values. Results too large for int64 use uint64 instead.
+func isMixedUnsigned64(x, y reflect.Type) bool {
+	xk, yk := x.Kind(), y.Kind()
+	switch {
+	case kindIsSigned(xk) && kindIsUnsigned(yk):
+		return y.Bits() == 64
+	case kindIsUnsigned(xk) && kindIsSigned(yk):
+		return x.Bits() == 64
+	}
+	return false
+}
+
+// Returns the width of the float part needed to hold all values of the type exactly, or 64 if a float32
+// cannot hold all of them.
+func floatBits(t reflect.Type) int {
+	switch k := t.Kind(); {
+	case k == reflect.Float32, k == reflect.Complex64:
+		return 32
+	case (kindIsSigned(k) || kindIsUnsigned(k)) && t.Bits() <= 16:
+		// float32 has 24 bits of mantissa
+		return 32
+	}
+	return 64
+}
+
+func kindIsNumeric(k reflect.Kind) bool {
+	return kindIsSigned(k) || kindIsUnsigned(k) || kindIsFloat(k) || kindIsComplex(k)
+}
+
+func kindIsSigned(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func kindIsUnsigned(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func kindIsFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func kindIsComplex(k reflect.Kind) bool {
+	return k == reflect.Complex64 || k == reflect.Complex128
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
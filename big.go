@@ -0,0 +1,259 @@
+package rprim
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+var (
+	typeBigInt   = reflect.TypeOf(big.Int{})
+	typeBigFloat = reflect.TypeOf(big.Float{})
+	typeBigRat   = reflect.TypeOf(big.Rat{})
+)
+
+// Checks if the type is one of the math/big number types (big.Int, big.Float or big.Rat).
+func IsBigType(t reflect.Type) bool {
+	return t == typeBigInt || t == typeBigFloat || t == typeBigRat
+}
+
+// Returns the conversion function between math/big numbers and primitive values, or nil if not supported.
+// All values are converted using a big.Rat as the intermediate representation, so no precision is lost.
+func (c Config) bigOp(srcType, dstType reflect.Type) ConvertOpFunc {
+	if !IsBigType(srcType) && !KindIsSimpleValue(srcType.Kind()) {
+		return nil
+	}
+	if !IsBigType(dstType) && !KindIsSimpleValue(dstType.Kind()) {
+		return nil
+	}
+
+	// big numbers are always checked when converting to fixed width types
+	overflow := c.Overflow
+	if overflow == OVERFLOW_WRAP {
+		overflow = OVERFLOW_ERROR
+	}
+	precision := c.precisionCheck()
+	lenient := (c.Flags & COP_LENIENT_NUMERIC_STRING) == COP_LENIENT_NUMERIC_STRING
+
+	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+		uv := UnderliningValue(v)
+		ut := UnderliningType(t)
+
+		// big numbers to string use their own formatting
+		if ut.Kind() == reflect.String && IsBigType(uv.Type()) {
+			return makeString(bigString(bigPointer(uv)), t)
+		}
+
+		r, err := bigRat(uv)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		// fractional strings are only accepted by integer destinations in lenient mode
+		if uv.Kind() == reflect.String && !r.IsInt() && !lenient && (ut == typeBigInt || kindIsInteger(ut.Kind())) {
+			return reflect.Value{}, fmt.Errorf("Error converting string to int: '%s' is not an integer", uv.String())
+		}
+
+		root, last := NewUnderliningValue(t)
+
+		switch {
+		case ut == typeBigInt:
+			i, err := roundRat(r, c.Rounding)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			last.Addr().Interface().(*big.Int).Set(i)
+			return root, nil
+		case ut == typeBigFloat:
+			last.Addr().Interface().(*big.Float).SetRat(r)
+			return root, nil
+		case ut == typeBigRat:
+			last.Addr().Interface().(*big.Rat).Set(r)
+			return root, nil
+		}
+
+		switch {
+		case kindIsInteger(ut.Kind()):
+			i, err := roundRat(r, c.Rounding)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			bits, err := bigIntRangeBits(i, ut, overflow)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return makeInt(bits, t)
+		case ut.Kind() == reflect.Float32 || ut.Kind() == reflect.Float64 || ut.Kind() == reflect.Complex64 || ut.Kind() == reflect.Complex128:
+			var f float64
+			var exact bool
+			if ut.Kind() == reflect.Float32 || ut.Kind() == reflect.Complex64 {
+				f32, ex := r.Float32()
+				f, exact = float64(f32), ex
+			} else {
+				f, exact = r.Float64()
+			}
+			if math.IsInf(f, 0) {
+				return reflect.Value{}, fmt.Errorf("Error converting %s to %s: %w", r.RatString(), ut.String(), ErrOverflow)
+			}
+			if !exact && precision != nil {
+				if err := precision(fmt.Errorf("Error converting %s to %s: %w", r.RatString(), ut.String(), ErrPrecisionLoss)); err != nil {
+					return reflect.Value{}, err
+				}
+			}
+			if ut.Kind() == reflect.Complex64 || ut.Kind() == reflect.Complex128 {
+				return makeComplex(complex(f, 0), t)
+			}
+			return makeFloat(f, t)
+		case ut.Kind() == reflect.String:
+			return makeString(r.RatString(), t)
+		}
+
+		return reflect.Value{}, fmt.Errorf("Invalid conversion from %s to %s", uv.Type().String(), t.String())
+	}
+}
+
+// Returns a pointer to the big number value.
+func bigPointer(v reflect.Value) interface{} {
+	if !v.CanAddr() {
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		v = p.Elem()
+	}
+	return v.Addr().Interface()
+}
+
+// Formats the big number pointer as a string.
+func bigString(b interface{}) string {
+	switch bv := b.(type) {
+	case *big.Int:
+		return bv.String()
+	case *big.Float:
+		return bv.Text('g', -1)
+	case *big.Rat:
+		return bv.RatString()
+	}
+	return ""
+}
+
+// Checks if the kind is a signed or unsigned integer.
+func kindIsInteger(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// Returns the value as an exact big.Rat.
+func bigRat(v reflect.Value) (*big.Rat, error) {
+	if IsBigType(v.Type()) {
+		switch bv := bigPointer(v).(type) {
+		case *big.Int:
+			return new(big.Rat).SetInt(bv), nil
+		case *big.Float:
+			if bv.IsInf() {
+				return nil, fmt.Errorf("Error converting %s: %w", bv.String(), ErrOverflow)
+			}
+			r, _ := bv.Rat(nil)
+			return r, nil
+		case *big.Rat:
+			return new(big.Rat).Set(bv), nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(v.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return floatRat(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		cv := v.Complex()
+		if imag(cv) != 0 {
+			return nil, fmt.Errorf("Error converting %v: imaginary part is not zero", cv)
+		}
+		return floatRat(real(cv))
+	case reflect.String:
+		r, ok := new(big.Rat).SetString(strings.TrimSpace(v.String()))
+		if !ok {
+			return nil, fmt.Errorf("Error converting string to number: invalid value '%s'", v.String())
+		}
+		return r, nil
+	}
+	return nil, fmt.Errorf("Invalid conversion from %s", v.Type().String())
+}
+
+func floatRat(f float64) (*big.Rat, error) {
+	if math.IsNaN(f) {
+		return nil, ErrNaN
+	}
+	if math.IsInf(f, 0) {
+		return nil, fmt.Errorf("Error converting %v: %w", f, ErrOverflow)
+	}
+	return new(big.Rat).SetFloat64(f), nil
+}
+
+// Rounds the rational value to an integer using the rounding mode.
+func roundRat(r *big.Rat, mode RoundingMode) (*big.Int, error) {
+	if r.IsInt() {
+		return new(big.Int).Set(r.Num()), nil
+	}
+
+	// q is truncated toward zero, m has the same sign as r
+	q, m := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	one := big.NewInt(int64(r.Sign()))
+
+	switch mode {
+	case ROUND_TRUNCATE:
+	case ROUND_FLOOR:
+		if r.Sign() < 0 {
+			q.Add(q, one)
+		}
+	case ROUND_CEIL:
+		if r.Sign() > 0 {
+			q.Add(q, one)
+		}
+	case ROUND_HALF_UP, ROUND_HALF_EVEN:
+		cmp := new(big.Int).Lsh(m.Abs(m), 1).Cmp(r.Denom())
+		if cmp > 0 || (cmp == 0 && (mode == ROUND_HALF_UP || q.Bit(0) == 1)) {
+			q.Add(q, one)
+		}
+	case ROUND_ERROR:
+		return nil, fmt.Errorf("Value %s is not integral", r.RatString())
+	default:
+		return nil, fmt.Errorf("Invalid rounding mode %d", int(mode))
+	}
+	return q, nil
+}
+
+// Returns the bits of the big integer to store in the integer type t, checking the range
+// using the overflow mode.
+func bigIntRangeBits(i *big.Int, t reflect.Type, overflow OverflowMode) (uint64, error) {
+	min, max, signed := intTypeRange(t)
+	if signed {
+		if !i.IsInt64() {
+			if overflow == OVERFLOW_SATURATE {
+				if i.Sign() < 0 {
+					return uint64(min), nil
+				}
+				return max, nil
+			}
+			return 0, overflowError(i, t)
+		}
+		return intRangeBits(i.Int64(), t, overflow)
+	}
+	if i.Sign() < 0 || !i.IsUint64() {
+		if overflow == OVERFLOW_SATURATE {
+			if i.Sign() < 0 {
+				return 0, nil
+			}
+			return max, nil
+		}
+		return 0, overflowError(i, t)
+	}
+	return uintRangeBits(i.Uint64(), t, overflow)
+}
@@ -0,0 +1,140 @@
+package rprim
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestBigToPrimitive(t *testing.T) {
+	bi, _ := new(big.Int).SetString("12345", 10)
+
+	cv, err := Convert(reflect.ValueOf(bi), reflect.TypeOf(uint64(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Uint() != 12345 {
+		t.Fatalf("Value should be 12345, is %d", cv.Uint())
+	}
+
+	cv, err = Convert(reflect.ValueOf(bi), reflect.TypeOf(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.String() != "12345" {
+		t.Fatalf("Value should be 12345, is %s", cv.String())
+	}
+
+	cv, err = Convert(reflect.ValueOf(big.NewRat(3, 4)), reflect.TypeOf(float64(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Float() != 0.75 {
+		t.Fatalf("Value should be 0.75, is %f", cv.Float())
+	}
+
+	cv, err = Convert(reflect.ValueOf(big.NewRat(3, 4)), reflect.TypeOf(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.String() != "3/4" {
+		t.Fatalf("Value should be 3/4, is %s", cv.String())
+	}
+
+	cv, err = NewConfig().SetRounding(ROUND_HALF_EVEN).Convert(reflect.ValueOf(big.NewFloat(2.5)), reflect.TypeOf(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Int() != 2 {
+		t.Fatalf("Value should be 2, is %d", cv.Int())
+	}
+}
+
+func TestBigOverflow(t *testing.T) {
+	// 2^100
+	bi := new(big.Int).Lsh(big.NewInt(1), 100)
+
+	_, err := Convert(reflect.ValueOf(bi), reflect.TypeOf(uint64(0)))
+	if !errors.Is(err, ErrOverflow) {
+		t.Fatalf("Expected overflow error, got %v", err)
+	}
+
+	_, err = Convert(reflect.ValueOf(big.NewInt(300)), reflect.TypeOf(uint8(0)))
+	if !errors.Is(err, ErrOverflow) {
+		t.Fatalf("Expected overflow error, got %v", err)
+	}
+
+	cv, err := NewConfig().SetOverflow(OVERFLOW_SATURATE).Convert(reflect.ValueOf(bi), reflect.TypeOf(uint64(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Uint() != math.MaxUint64 {
+		t.Fatalf("Value should be MaxUint64, is %d", cv.Uint())
+	}
+
+	_, err = Convert(reflect.ValueOf(new(big.Int).Lsh(big.NewInt(1), 2000)), reflect.TypeOf(float32(0)))
+	if !errors.Is(err, ErrOverflow) {
+		t.Fatalf("Expected overflow error, got %v", err)
+	}
+}
+
+func TestPrimitiveToBig(t *testing.T) {
+	var bi *big.Int
+	cv, err := Convert(reflect.ValueOf(uint64(math.MaxUint64)), reflect.TypeOf(bi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bi = cv.Interface().(*big.Int)
+	if bi.String() != "18446744073709551615" {
+		t.Fatalf("Value should be 18446744073709551615, is %s", bi.String())
+	}
+
+	var br *big.Rat
+	cv, err = Convert(reflect.ValueOf("3/4"), reflect.TypeOf(br))
+	if err != nil {
+		t.Fatal(err)
+	}
+	br = cv.Interface().(*big.Rat)
+	if br.Cmp(big.NewRat(3, 4)) != 0 {
+		t.Fatalf("Value should be 3/4, is %s", br.String())
+	}
+
+	var bf *big.Float
+	cv, err = Convert(reflect.ValueOf(0.5), reflect.TypeOf(bf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bf = cv.Interface().(*big.Float)
+	if f, _ := bf.Float64(); f != 0.5 {
+		t.Fatalf("Value should be 0.5, is %s", bf.String())
+	}
+
+	cv, err = Convert(reflect.ValueOf(big.NewRat(7, 2)), reflect.TypeOf(bi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Interface().(*big.Int).Int64() != 3 {
+		t.Fatalf("Value should be 3, is %s", cv.Interface().(*big.Int).String())
+	}
+
+	_, err = Convert(reflect.ValueOf("3/4"), reflect.TypeOf(bi))
+	if err == nil {
+		t.Fatal("Expected error converting fractional string to big.Int")
+	}
+
+	_, err = Convert(reflect.ValueOf(math.NaN()), reflect.TypeOf(bi))
+	if !errors.Is(err, ErrNaN) {
+		t.Fatalf("Expected NaN error, got %v", err)
+	}
+
+	var nilbi *big.Int
+	cv, err = Convert(reflect.ValueOf(nilbi), reflect.TypeOf(br))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cv.IsNil() {
+		t.Fatal("Value should be nil")
+	}
+}
@@ -0,0 +1,111 @@
+package rprim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Optional parameters for channel conversion.
+type ChanOptions struct {
+	// Buffer size of the destination channel.
+	Buffer int
+	// Channel that receives conversion errors. Sending blocks until received or the context is done.
+	Errors chan<- error
+	// Function called with conversion errors, called on the converting goroutine.
+	OnError func(err error)
+}
+
+// Converts all elements received from the src channel, sending them to the returned receive-only
+// channel of dstType elements.
+// Elements are converted on a new goroutine, which closes the returned channel when src is closed or
+// ctx is done. The converter is selected only once when the element type allows it.
+// Elements that fail to convert are skipped, and the error, with the element index as its path, is sent
+// to opts.Errors and opts.OnError if set.
+func (c *Config) ConvertChan(ctx context.Context, src interface{}, dstType reflect.Type, opts *ChanOptions) (reflect.Value, error) {
+	if opts == nil {
+		opts = &ChanOptions{}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	srcV := reflect.ValueOf(src)
+	if srcV.Kind() != reflect.Chan {
+		return reflect.Value{}, fmt.Errorf("Source must be a channel, is %s", srcV.Kind().String())
+	}
+	if srcV.IsNil() {
+		return reflect.Value{}, errors.New("Source channel is nil")
+	}
+	if srcV.Type().ChanDir()&reflect.RecvDir == 0 {
+		return reflect.Value{}, errors.New("Source channel must allow receiving")
+	}
+	srcItemType := srcV.Type().Elem()
+
+	// nillable source items may select a different converter for each item
+	var cop ConvertOpFunc
+	if srcItemType.Kind() != reflect.Ptr && srcItemType.Kind() != reflect.Interface {
+		cop = c.ConvertOpType(reflect.Zero(srcItemType), dstType)
+		if cop == nil {
+			return reflect.Value{}, fmt.Errorf("Invalid conversion from %s to %s", srcItemType.String(), dstType.String())
+		}
+	} else if !c.CanConvert(srcItemType, dstType) {
+		return reflect.Value{}, fmt.Errorf("Invalid conversion from %s to %s", srcItemType.String(), dstType.String())
+	}
+
+	dstV := reflect.MakeChan(reflect.ChanOf(reflect.BothDir, dstType), opts.Buffer)
+
+	convertItem := func(item reflect.Value) (reflect.Value, error) {
+		icop := cop
+		if icop == nil {
+			icop = c.ConvertOpType(item, dstType)
+			if icop == nil {
+				return reflect.Value{}, fmt.Errorf("Invalid conversion from %s to %s", item.Type().String(), dstType.String())
+			}
+		}
+		return icop(item, dstType)
+	}
+
+	done := reflect.ValueOf(ctx.Done())
+	recvCases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: done},
+		{Dir: reflect.SelectRecv, Chan: srcV},
+	}
+
+	go func() {
+		defer dstV.Close()
+		for i := 0; ; i++ {
+			chosen, item, ok := reflect.Select(recvCases)
+			if chosen == 0 || !ok {
+				return
+			}
+
+			cv, err := convertItem(item)
+			if err != nil {
+				err = withPath(fmt.Sprintf("[%d]", i), err)
+				if opts.OnError != nil {
+					opts.OnError(err)
+				}
+				if opts.Errors != nil {
+					select {
+					case opts.Errors <- err:
+					case <-ctx.Done():
+						return
+					}
+				}
+				continue
+			}
+
+			sendCases := []reflect.SelectCase{
+				{Dir: reflect.SelectRecv, Chan: done},
+				{Dir: reflect.SelectSend, Chan: dstV, Send: cv},
+			}
+			if chosen, _, _ := reflect.Select(sendCases); chosen == 0 {
+				return
+			}
+		}
+	}()
+
+	return dstV.Convert(reflect.ChanOf(reflect.RecvDir, dstType)), nil
+}
@@ -0,0 +1,96 @@
+package rprim
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestConvertChan(t *testing.T) {
+	src := make(chan string)
+	errs := make(chan error, 10)
+
+	cv, err := ConvertChan(context.Background(), src, reflect.TypeOf(float64(0)), &ChanOptions{Errors: errs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := cv.Interface().(<-chan float64)
+
+	go func() {
+		for _, s := range []string{"1.5", "x", "3"} {
+			src <- s
+		}
+		close(src)
+	}()
+
+	var ret []float64
+	for v := range dst {
+		ret = append(ret, v)
+	}
+	if !reflect.DeepEqual(ret, []float64{1.5, 3}) {
+		t.Fatalf("Invalid values %v", ret)
+	}
+
+	select {
+	case err := <-errs:
+		var perr *PathError
+		if !errors.As(err, &perr) || perr.Path != "[1]" {
+			t.Fatalf("Expected path error for item 1, got %v", err)
+		}
+	default:
+		t.Fatal("Expected conversion error")
+	}
+}
+
+func TestConvertChanInterface(t *testing.T) {
+	src := make(chan interface{}, 3)
+	src <- 1
+	src <- "2"
+	src <- int8(3)
+	close(src)
+
+	var errCount int
+	cv, err := ConvertChan(context.Background(), src, reflect.TypeOf(""), &ChanOptions{
+		OnError: func(err error) { errCount++ },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ret []string
+	for v := range cv.Interface().(<-chan string) {
+		ret = append(ret, v)
+	}
+	if !reflect.DeepEqual(ret, []string{"1", "2", "3"}) || errCount != 0 {
+		t.Fatalf("Invalid values %v (%d errors)", ret, errCount)
+	}
+}
+
+func TestConvertChanCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	src := make(chan int)
+
+	cv, err := ConvertChan(ctx, src, reflect.TypeOf(""), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := cv.Interface().(<-chan string)
+
+	src <- 1
+	if v := <-dst; v != "1" {
+		t.Fatalf("Value should be 1, is %s", v)
+	}
+	cancel()
+	if _, ok := <-dst; ok {
+		t.Fatal("Destination channel should be closed")
+	}
+}
+
+func TestConvertChanInvalid(t *testing.T) {
+	if _, err := ConvertChan(context.Background(), make(chan complex64), reflect.TypeOf(int(0)), nil); err == nil {
+		t.Fatal("Expected conversion error")
+	}
+	if _, err := ConvertChan(context.Background(), []int{}, reflect.TypeOf(int(0)), nil); err == nil {
+		t.Fatal("Expected non-channel error")
+	}
+}
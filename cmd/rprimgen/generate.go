@@ -0,0 +1,615 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/RangelReale/rprim"
+)
+
+// Prefix of the directive comments read from the package source files.
+const directivePrefix = "//rprimgen:"
+
+// Conversion options, with the same meaning as the rprim.Config fields.
+type options struct {
+	flags         uint
+	floatFormat   string
+	complexFormat string
+}
+
+// Requested conversion function.
+type conversion struct {
+	name string
+	src  ast.Expr
+	dst  ast.Expr
+	opts options
+	pos  string
+}
+
+// Generated function for a source and destination pair.
+type pairFunc struct {
+	name string
+	src  *genType
+	dst  *genType
+	opts options
+}
+
+type generator struct {
+	pkgName     string
+	types       map[string]ast.Expr
+	conversions []*conversion
+
+	funcs    []*pairFunc
+	funcKeys map[string]*pairFunc
+	imports  map[string]bool
+	helpers  bool
+}
+
+func newGenerator() *generator {
+	return &generator{
+		types:    map[string]ast.Expr{},
+		funcKeys: map[string]*pairFunc{},
+		imports:  map[string]bool{},
+	}
+}
+
+// Parses the package in dir, reading the declared types and the directive comments.
+// The output file is skipped, so it doesn't need to compile.
+func (g *generator) parseDir(dir, output string, directives bool) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") || filepath.Base(file) == filepath.Base(output) {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		if g.pkgName == "" {
+			g.pkgName = f.Name.Name
+		}
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if ts.TypeParams == nil && !ts.Assign.IsValid() {
+					g.types[ts.Name.Name] = ts.Type
+				}
+			}
+		}
+		if !directives {
+			continue
+		}
+
+		opts := defaultOptions()
+		for _, cg := range f.Comments {
+			for _, c := range cg.List {
+				if !strings.HasPrefix(c.Text, directivePrefix) {
+					continue
+				}
+				pos := fset.Position(c.Pos()).String()
+				if err := g.parseCommand(strings.TrimPrefix(c.Text, directivePrefix), &opts, pos); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if g.pkgName == "" {
+		return fmt.Errorf("No Go files found in %s", dir)
+	}
+	return nil
+}
+
+// Reads the commands from a configuration file, one per line, with the same syntax as the directives.
+func (g *generator) parseConfig(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	opts := defaultOptions()
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if err := g.parseCommand(text, &opts, fmt.Sprintf("%s:%d", filename, line)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func defaultOptions() options {
+	c := rprim.NewConfig()
+	return options{
+		flags:         c.Flags,
+		floatFormat:   c.FloatFormat,
+		complexFormat: c.ComplexFormat,
+	}
+}
+
+// Parses a single command. Options apply to all the conversions that follow them.
+//
+//	flags COP_ALLOW_NIL_TO_ZERO_VALUE|COP_ZERO_TO_NIL
+//	floatformat %.2f
+//	complexformat %g
+//	convert FuncName SrcType DstType
+func (g *generator) parseCommand(text string, opts *options, pos string) error {
+	cmd, arg, _ := strings.Cut(strings.TrimSpace(text), " ")
+	arg = strings.TrimSpace(arg)
+	switch cmd {
+	case "flags":
+		flags, err := parseFlags(arg)
+		if err != nil {
+			return fmt.Errorf("%s: %v", pos, err)
+		}
+		opts.flags = flags
+	case "floatformat":
+		opts.floatFormat = arg
+	case "complexformat":
+		opts.complexFormat = arg
+	case "convert":
+		fields := strings.Fields(arg)
+		if len(fields) != 3 {
+			return fmt.Errorf("%s: convert requires a function name, source type and destination type", pos)
+		}
+		src, err := parser.ParseExpr(fields[1])
+		if err != nil {
+			return fmt.Errorf("%s: invalid source type %s: %v", pos, fields[1], err)
+		}
+		dst, err := parser.ParseExpr(fields[2])
+		if err != nil {
+			return fmt.Errorf("%s: invalid destination type %s: %v", pos, fields[2], err)
+		}
+		g.conversions = append(g.conversions, &conversion{
+			name: fields[0],
+			src:  src,
+			dst:  dst,
+			opts: *opts,
+			pos:  pos,
+		})
+	default:
+		return fmt.Errorf("%s: unknown command '%s'", pos, cmd)
+	}
+	return nil
+}
+
+var flagValues = map[string]uint{
+	"COP_ALLOW_NIL_TO_ZERO_VALUE": rprim.COP_ALLOW_NIL_TO_ZERO_VALUE,
+	"COP_ALLOW_STRING_TO_SLICE":   rprim.COP_ALLOW_STRING_TO_SLICE,
+	"COP_ALLOW_SLICE_TO_SRING":    rprim.COP_ALLOW_SLICE_TO_SRING,
+	"COP_LENIENT_NUMERIC_STRING":  rprim.COP_LENIENT_NUMERIC_STRING,
+	"COP_COLLECT_ERRORS":          rprim.COP_COLLECT_ERRORS,
+	"COP_ZERO_TO_NIL":             rprim.COP_ZERO_TO_NIL,
+}
+
+func parseFlags(s string) (uint, error) {
+	var flags uint
+	for _, name := range strings.Split(s, "|") {
+		name = strings.TrimSpace(name)
+		if name == "" || name == "0" {
+			continue
+		}
+		f, ok := flagValues[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown flag '%s'", name)
+		}
+		flags |= f
+	}
+	return flags, nil
+}
+
+// Generates the Go source of all the requested conversions.
+func (g *generator) generate() ([]byte, error) {
+	for _, c := range g.conversions {
+		src, err := g.resolve(c.src)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", c.pos, err)
+		}
+		dst, err := g.resolve(c.dst)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", c.pos, err)
+		}
+		if _, exists := g.funcKeys[c.name]; exists {
+			return nil, fmt.Errorf("%s: duplicated function name %s", c.pos, c.name)
+		}
+		f := &pairFunc{name: c.name, src: src, dst: dst, opts: c.opts}
+		g.funcKeys[c.name] = f
+		g.funcs = append(g.funcs, f)
+	}
+
+	var body bytes.Buffer
+	// funcs may grow while generating, with the struct field conversions
+	for i := 0; i < len(g.funcs); i++ {
+		if err := g.genFunc(&body, g.funcs[i], i < len(g.conversions)); err != nil {
+			if i < len(g.conversions) {
+				return nil, fmt.Errorf("%s: %v", g.conversions[i].pos, err)
+			}
+			return nil, err
+		}
+	}
+	if g.helpers {
+		g.imports["github.com/RangelReale/rprim"] = true
+		body.WriteString(pathHelpers)
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by rprimgen. DO NOT EDIT.\n\npackage %s\n\n", g.pkgName)
+	if len(g.imports) > 0 {
+		// standard library imports first, in a separate group
+		var std, other []string
+		for imp := range g.imports {
+			if strings.Contains(imp, ".") {
+				other = append(other, imp)
+			} else {
+				std = append(std, imp)
+			}
+		}
+		sort.Strings(std)
+		sort.Strings(other)
+		out.WriteString("import (\n")
+		for _, imp := range std {
+			fmt.Fprintf(&out, "\t%s\n", strconv.Quote(imp))
+		}
+		if len(std) > 0 && len(other) > 0 {
+			out.WriteString("\n")
+		}
+		for _, imp := range other {
+			fmt.Fprintf(&out, "\t%s\n", strconv.Quote(imp))
+		}
+		out.WriteString(")\n\n")
+	}
+	out.Write(body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("Error formatting generated source: %v", err)
+	}
+	return src, nil
+}
+
+// Returns the function that converts between the types, adding it if not generated yet.
+func (g *generator) pairFunc(src, dst *genType, opts options) *pairFunc {
+	key := fmt.Sprintf("%s|%s|%d|%s|%s", src.expr, dst.expr, opts.flags, opts.floatFormat, opts.complexFormat)
+	if f, ok := g.funcKeys[key]; ok {
+		return f
+	}
+	f := &pairFunc{
+		name: fmt.Sprintf("rprimgenConvert%d", len(g.funcs)-len(g.conversions)+1),
+		src:  src,
+		dst:  dst,
+		opts: opts,
+	}
+	g.funcKeys[key] = f
+	g.funcs = append(g.funcs, f)
+	return f
+}
+
+// Generates the function, following the same steps as rprim.Config.ConvertOpType.
+func (g *generator) genFunc(w *bytes.Buffer, f *pairFunc, requested bool) error {
+	src, dst, opts := f.src, f.dst, f.opts
+
+	var b bytes.Buffer
+	if requested {
+		fmt.Fprintf(&b, "// %s converts %s to %s.\n", f.name, src.expr, dst.expr)
+	}
+	fmt.Fprintf(&b, "func %s(src %s) (%s, error) {\n", f.name, src.expr, dst.expr)
+
+	zero := dst.zero()
+	v := "src"
+	if src.ptr > 0 {
+		// only a nil source follows the nil rules
+		b.WriteString("if src == nil {\n")
+		switch {
+		case dst.ptr > 0:
+			b.WriteString("return nil, nil\n")
+		case opts.flags&rprim.COP_ALLOW_NIL_TO_ZERO_VALUE != 0:
+			fmt.Fprintf(&b, "return %s, nil\n", zero)
+		default:
+			g.imports["errors"] = true
+			fmt.Fprintf(&b, "return %s, errors.New(\"Copying nil to zero value not allowed\")\n", zero)
+		}
+		b.WriteString("}\n")
+		if src.ptr > 1 {
+			var checks []string
+			for i := 1; i < src.ptr; i++ {
+				checks = append(checks, strings.Repeat("*", i)+"src == nil")
+			}
+			g.imports["github.com/RangelReale/rprim"] = true
+			fmt.Fprintf(&b, "if %s {\nreturn %s, rprim.ErrNilPointerChain\n}\n", strings.Join(checks, " || "), zero)
+		}
+		fmt.Fprintf(&b, "v := %ssrc\n", strings.Repeat("*", src.ptr))
+		v = "v"
+	}
+
+	if dst.ptr > 0 && opts.flags&rprim.COP_ZERO_TO_NIL != 0 && kindIsSimpleValue(src.kind) {
+		fmt.Fprintf(&b, "if %s {\nreturn nil, nil\n}\n", src.isZero(v))
+	}
+
+	if err := g.genConvert(&b, v, src, dst, opts); err != nil {
+		return err
+	}
+
+	if dst.ptr == 0 {
+		b.WriteString("return r, nil\n")
+	} else {
+		last := "r"
+		for i := 1; i <= dst.ptr; i++ {
+			fmt.Fprintf(&b, "p%d := &%s\n", i, last)
+			last = fmt.Sprintf("p%d", i)
+		}
+		fmt.Fprintf(&b, "return %s, nil\n", last)
+	}
+	b.WriteString("}\n\n")
+
+	w.Write(b.Bytes())
+	return nil
+}
+
+// Generates the conversion of the underlying value v to the variable r of the destination base type.
+// Error returns use the destination zero value.
+func (g *generator) genConvert(b *bytes.Buffer, v string, src, dst *genType, opts options) error {
+	sk, dk := src.kind, dst.kind
+	zero := dst.zero()
+	invalid := fmt.Errorf("Invalid conversion from %s to %s", src.expr, dst.expr)
+
+	// bool is only assigned directly in struct fields
+	if sk == reflect.Bool || dk == reflect.Bool {
+		return invalid
+	}
+
+	if sk == reflect.Struct || dk == reflect.Struct {
+		if sk != dk {
+			return fmt.Errorf("Conversion from %s to %s is not supported by rprimgen", src.expr, dst.expr)
+		}
+		if src.base == dst.base {
+			fmt.Fprintf(b, "r := %s\n", v)
+			return nil
+		}
+		if g.isWrapper(src) && g.isWrapper(dst) {
+			return fmt.Errorf("Conversion between wrapper structs %s and %s is not supported by rprimgen", src.expr, dst.expr)
+		}
+		return g.genStruct(b, v, src, dst, opts)
+	}
+
+	if sk == reflect.Slice && dk == reflect.Slice {
+		if src.base != dst.base {
+			return fmt.Errorf("Conversion from %s to %s is not supported by rprimgen", src.expr, dst.expr)
+		}
+		fmt.Fprintf(b, "r := %s\n", v)
+		return nil
+	}
+
+	// same underlying type, assigned directly
+	if src.base == dst.base {
+		fmt.Fprintf(b, "r := %s\n", v)
+		return nil
+	}
+
+	switch {
+	case kindIsSigned(sk) || kindIsUnsigned(sk):
+		switch {
+		case kindIsSigned(dk) || kindIsUnsigned(dk):
+			fmt.Fprintf(b, "r := %s\n", conv(dst.base, src.base, v))
+		case kindIsFloat(dk):
+			fmt.Fprintf(b, "r := %s\n", conv(dst.base, "float64", conv("float64", src.base, v)))
+		case dk == reflect.String:
+			g.imports["strconv"] = true
+			if kindIsSigned(sk) {
+				fmt.Fprintf(b, "r := %s\n", conv(dst.base, "string", fmt.Sprintf("strconv.FormatInt(%s, 10)", conv("int64", src.base, v))))
+			} else {
+				fmt.Fprintf(b, "r := %s\n", conv(dst.base, "string", fmt.Sprintf("strconv.FormatUint(%s, 10)", conv("uint64", src.base, v))))
+			}
+		default:
+			return invalid
+		}
+
+	case kindIsFloat(sk):
+		switch {
+		case kindIsSigned(dk):
+			g.imports["math"] = true
+			fmt.Fprintf(b, "r := %s\n", conv(dst.base, "int64", fmt.Sprintf("int64(math.Trunc(%s))", conv("float64", src.base, v))))
+		case kindIsUnsigned(dk):
+			g.imports["math"] = true
+			fmt.Fprintf(b, "r := %s\n", conv(dst.base, "uint64", fmt.Sprintf("uint64(math.Trunc(%s))", conv("float64", src.base, v))))
+		case kindIsFloat(dk):
+			fmt.Fprintf(b, "r := %s\n", conv(dst.base, "float64", conv("float64", src.base, v)))
+		case dk == reflect.String:
+			g.imports["fmt"] = true
+			fmt.Fprintf(b, "r := %s\n", conv(dst.base, "string", fmt.Sprintf("fmt.Sprintf(%s, %s)", strconv.Quote(opts.floatFormat), conv("float64", src.base, v))))
+		default:
+			return invalid
+		}
+
+	case kindIsComplex(sk):
+		switch {
+		case kindIsComplex(dk):
+			fmt.Fprintf(b, "r := %s\n", conv(dst.base, "complex128", conv("complex128", src.base, v)))
+		case dk == reflect.String:
+			g.imports["fmt"] = true
+			fmt.Fprintf(b, "r := %s\n", conv(dst.base, "string", fmt.Sprintf("fmt.Sprintf(%s, %s)", strconv.Quote(opts.complexFormat), conv("complex128", src.base, v))))
+		default:
+			return invalid
+		}
+
+	case sk == reflect.String:
+		str := conv("string", src.base, v)
+		switch {
+		case kindIsSigned(dk) || kindIsUnsigned(dk):
+			g.imports["fmt"] = true
+			g.imports["strconv"] = true
+			parse, bits, name := "ParseInt", "int64", "int"
+			if kindIsUnsigned(dk) {
+				parse, bits, name = "ParseUint", "uint64", "uint"
+			}
+			fmt.Fprintf(b, "var r %s\n", dst.base)
+			fmt.Fprintf(b, "if i, err := strconv.%s(%s, 10, 64); err == nil {\nr = %s\n", parse, str, conv(dst.base, bits, "i"))
+			if opts.flags&rprim.COP_LENIENT_NUMERIC_STRING != 0 {
+				// fractional values are parsed as float and truncated
+				g.imports["errors"] = true
+				g.imports["math"] = true
+				fmt.Fprintf(b, "} else if errors.Is(err, strconv.ErrRange) {\n")
+				fmt.Fprintf(b, "return %s, fmt.Errorf(\"Error converting string to %s: %%w\", err)\n", zero, name)
+				fmt.Fprintf(b, "} else if f, err := strconv.ParseFloat(%s, 64); err == nil {\n", str)
+				fmt.Fprintf(b, "r = %s\n", conv(dst.base, bits, fmt.Sprintf("%s(math.Trunc(f))", bits)))
+				fmt.Fprintf(b, "} else {\n")
+				fmt.Fprintf(b, "return %s, fmt.Errorf(\"Error converting string to %s: %%v\", err)\n", zero, name)
+			} else {
+				fmt.Fprintf(b, "} else {\n")
+				fmt.Fprintf(b, "return %s, fmt.Errorf(\"Error converting string to %s: %%w\", err)\n", zero, name)
+			}
+			b.WriteString("}\n")
+		case kindIsFloat(dk) || kindIsComplex(dk):
+			g.imports["fmt"] = true
+			typ, format, name := "float64", opts.floatFormat, "float"
+			if kindIsComplex(dk) {
+				typ, format, name = "complex128", opts.complexFormat, "complex"
+			}
+			fmt.Fprintf(b, "var cv %s\n", typ)
+			fmt.Fprintf(b, "if _, err := fmt.Sscanf(%s, %s, &cv); err != nil {\n", str, strconv.Quote(format))
+			fmt.Fprintf(b, "return %s, fmt.Errorf(\"Error converting string to %s: %%v\", err)\n", zero, name)
+			b.WriteString("}\n")
+			fmt.Fprintf(b, "r := %s\n", conv(dst.base, typ, "cv"))
+		case dk == reflect.String:
+			fmt.Fprintf(b, "r := %s\n", conv(dst.base, src.base, v))
+		case dk == reflect.Slice:
+			if opts.flags&rprim.COP_ALLOW_STRING_TO_SLICE == 0 {
+				return fmt.Errorf("%v, needs flag COP_ALLOW_STRING_TO_SLICE", invalid)
+			}
+			fmt.Fprintf(b, "r := %s(%s)\n", dst.base, str)
+		default:
+			return invalid
+		}
+
+	case sk == reflect.Slice:
+		if dk != reflect.String {
+			return invalid
+		}
+		if opts.flags&rprim.COP_ALLOW_SLICE_TO_SRING == 0 {
+			return fmt.Errorf("%v, needs flag COP_ALLOW_SLICE_TO_SRING", invalid)
+		}
+		fmt.Fprintf(b, "r := %s\n", conv(dst.base, "string", fmt.Sprintf("string(%s)", v)))
+
+	default:
+		return invalid
+	}
+	return nil
+}
+
+// Returns the Go source converting expr, of type from, to type to.
+func conv(to, from, expr string) string {
+	if to == from {
+		return expr
+	}
+	return fmt.Sprintf("%s(%s)", to, expr)
+}
+
+// Generates the struct conversion, converting exported fields with the same name.
+func (g *generator) genStruct(b *bytes.Buffer, v string, src, dst *genType, opts options) error {
+	srcFields := map[string]genField{}
+	for _, f := range src.fields() {
+		if f.embedded {
+			return fmt.Errorf("Embedded field %s of %s is not supported by rprimgen", f.name, src.base)
+		}
+		srcFields[f.name] = f
+	}
+
+	collect := opts.flags&rprim.COP_COLLECT_ERRORS != 0
+	zero := dst.zero()
+	g.helpers = true
+
+	fmt.Fprintf(b, "var r %s\n", dst.base)
+	if collect {
+		b.WriteString("var errs rprim.Errors\n")
+	}
+	for _, df := range dst.fields() {
+		if df.embedded {
+			return fmt.Errorf("Embedded field %s of %s is not supported by rprimgen", df.name, dst.base)
+		}
+		if !ast.IsExported(df.name) {
+			continue
+		}
+		sf, ok := srcFields[df.name]
+		if !ok {
+			continue
+		}
+		sft, err := g.resolve(sf.typ)
+		if err != nil {
+			return fmt.Errorf("Field %s.%s: %v", src.base, sf.name, err)
+		}
+		dft, err := g.resolve(df.typ)
+		if err != nil {
+			return fmt.Errorf("Field %s.%s: %v", dst.base, df.name, err)
+		}
+
+		if sft.kind == reflect.Bool || dft.kind == reflect.Bool {
+			// bool is assigned directly, without the nil rules
+			if sft.expr != dft.expr || sft.ptr > 0 {
+				return fmt.Errorf("Field %s: Invalid conversion from %s to %s", df.name, sft.expr, dft.expr)
+			}
+			fmt.Fprintf(b, "r.%s = %s.%s\n", df.name, v, sf.name)
+			continue
+		}
+
+		pf := g.pairFunc(sft, dft, opts)
+		fmt.Fprintf(b, "if cv, err := %s(%s.%s); err != nil {\n", pf.name, v, sf.name)
+		if collect {
+			fmt.Fprintf(b, "errs = rprimgenAppendErrors(errs, rprimgenWithPath(%s, err))\n", strconv.Quote("."+df.name))
+		} else {
+			fmt.Fprintf(b, "return %s, rprimgenWithPath(%s, err)\n", zero, strconv.Quote("."+df.name))
+		}
+		fmt.Fprintf(b, "} else {\nr.%s = cv\n}\n", df.name)
+	}
+	if collect {
+		fmt.Fprintf(b, "if len(errs) > 0 {\nreturn %s, errs\n}\n", zero)
+	}
+	return nil
+}
+
+// Helpers used by the struct conversions, with the same rules as the rprim error paths.
+const pathHelpers = `// rprimgenWithPath adds the path prefix to the error, or to all errors of a list.
+func rprimgenWithPath(prefix string, err error) error {
+	switch perr := err.(type) {
+	case *rprim.PathError:
+		return &rprim.PathError{Path: prefix + perr.Path, Err: perr.Err}
+	case rprim.Errors:
+		ret := make(rprim.Errors, len(perr))
+		for i, e := range perr {
+			ret[i] = rprimgenWithPath(prefix, e)
+		}
+		return ret
+	}
+	return &rprim.PathError{Path: prefix, Err: err}
+}
+
+// rprimgenAppendErrors appends the error to the list, flattening lists.
+func rprimgenAppendErrors(errs rprim.Errors, err error) rprim.Errors {
+	if el, ok := err.(rprim.Errors); ok {
+		return append(errs, el...)
+	}
+	return append(errs, err)
+}
+`
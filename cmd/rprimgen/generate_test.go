@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The generated file of internal/paritytest is the golden file, and its parity with the reflective
+// conversions is tested in that package.
+func TestGenerateGolden(t *testing.T) {
+	dir := filepath.Join("internal", "paritytest")
+	output := filepath.Join(dir, "rprim_gen.go")
+
+	src, err := generateDir(dir, "", output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, golden) {
+		t.Fatalf("Generated source differs from %s, run go generate in that directory", output)
+	}
+}
+
+func TestGenerateConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "types.go"), []byte("package sample\n\ntype ID int64\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(dir, "rprimgen.conf")
+	if err := os.WriteFile(config, []byte("# sample\nflags COP_ZERO_TO_NIL\nconvert StringToID string *ID\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := generateDir(dir, config, filepath.Join(dir, "rprim_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"package sample", "func StringToID(src string) (*ID, error) {", `if src == "" {`} {
+		if !strings.Contains(string(src), expected) {
+			t.Fatalf("Generated source should contain %q:\n%s", expected, src)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		command string
+	}{
+		{"unknown type", "convert F Unknown string"},
+		{"unsupported pair", "convert F complex64 int"},
+		{"missing flag", "convert F string []byte"},
+		{"struct to int", "convert F S int"},
+		{"unknown flag", "flags COP_INVALID"},
+		{"unknown command", "invalid"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newGenerator()
+			g.pkgName = "sample"
+			g.types["S"] = &ast.StructType{Fields: &ast.FieldList{}}
+			opts := defaultOptions()
+			err := g.parseCommand(tc.command, &opts, "test")
+			if err == nil {
+				_, err = g.generate()
+			}
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package paritytest
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/RangelReale/rprim"
+)
+
+// Checks that the generated function returns the same results as the rprim.Config conversion,
+// for all the inputs.
+func checkParity(t *testing.T, config *rprim.Config, fn interface{}, inputs ...interface{}) {
+	t.Helper()
+	fv := reflect.ValueOf(fn)
+	dstType := fv.Type().Out(0)
+	for _, in := range inputs {
+		iv := reflect.New(fv.Type().In(0)).Elem()
+		if in != nil {
+			iv.Set(reflect.ValueOf(in))
+		}
+
+		out := fv.Call([]reflect.Value{iv})
+		gerr, _ := out[1].Interface().(error)
+
+		cv, err := config.Convert(iv, dstType)
+		if (gerr == nil) != (err == nil) || (err != nil && gerr.Error() != err.Error()) {
+			t.Fatalf("%s(%#v): generated error %v, rprim error %v", fv.Type().String(), in, gerr, err)
+		}
+		if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(out[0].Interface(), cv.Interface()) {
+			t.Fatalf("%s(%#v): generated %#v, rprim %#v", fv.Type().String(), in, out[0].Interface(), cv.Interface())
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestParityDefault(t *testing.T) {
+	c := rprim.NewConfig()
+
+	checkParity(t, c, IntToString, 0, -15, math.MaxInt64)
+	checkParity(t, c, Int64PtrToInt8, nil, ptr(int64(12)), ptr(int64(300)), ptr(int64(-129)))
+	checkParity(t, c, Uint32ToFloat32, uint32(0), uint32(16777217), uint32(math.MaxUint32))
+	checkParity(t, c, Float64ToInt16, 0.0, 12.9, -12.9, 40000.5, math.Inf(1))
+	checkParity(t, c, Float64ToUint8, 0.0, 12.9, 300.2)
+	checkParity(t, c, Float32ToFloat64PtrPtr, float32(0), float32(1.1), float32(-3.5))
+	checkParity(t, c, Float64ToString, 0.0, 1.5, -2.25, math.NaN())
+	checkParity(t, c, Complex128ToString, complex(1, 2), complex(0, -1.5))
+	checkParity(t, c, Complex128ToComplex64, complex(1.1, 2.2))
+	checkParity(t, c, StringToInt, "", "12", "-12", "1.5", "x", "99999999999999999999")
+	checkParity(t, c, StringToUint16, "12", "70000", "-1", "x")
+	checkParity(t, c, StringToFloat32Ptr, "1.5", "-2", "x", "")
+	checkParity(t, c, StringToComplex64, "(1+2i)", "x")
+	checkParity(t, c, CelsiusToLabel, Celsius(0), Celsius(36.6))
+	checkParity(t, c, LabelPtrToCelsius, nil, ptr(Label("36.6")), ptr(Label("hot")))
+	checkParity(t, c, IntPtrToInt, nil, ptr(5))
+
+	checkParity(t, c, UserRowToUser,
+		nil,
+		&UserRow{},
+		&UserRow{ID: "10", Name: ptr("John"), Score: 9.5, Active: true, Address: &AddressRow{Street: "Main", Number: "12"}, Comment: "x", secret: "s"},
+		&UserRow{ID: "10", Name: ptr("John"), Address: &AddressRow{Street: "Main", Number: "x"}},
+		&UserRow{ID: "x", Name: ptr("John")},
+	)
+}
+
+func TestParityFlags(t *testing.T) {
+	c := rprim.NewConfig().SetFlags(rprim.COP_ALLOW_NIL_TO_ZERO_VALUE | rprim.COP_ALLOW_STRING_TO_SLICE |
+		rprim.COP_ALLOW_SLICE_TO_SRING | rprim.COP_LENIENT_NUMERIC_STRING | rprim.COP_ZERO_TO_NIL)
+	c.FloatFormat = "%.2f"
+
+	checkParity(t, c, IntPtrPtrToIntZero, nil, ptr(ptr(7)), ptr((*int)(nil)))
+	checkParity(t, c, StringToInt32Lenient, "12", "12.9", "-12.9", "x", "99999999999999999999", "3000000000")
+	checkParity(t, c, StringToUint64Lenient, "12", "12.9", "x", "99999999999999999999")
+	checkParity(t, c, StringToBytes, "", "abc")
+	checkParity(t, c, StringToRunes, nil, ptr("ação"))
+	checkParity(t, c, RunesToString, []rune(nil), []rune("ação"))
+	checkParity(t, c, Float32ToStringFormat, float32(0), float32(1.005), float32(-3))
+	checkParity(t, c, StringToFloat64Format, "1.25", "x")
+	checkParity(t, c, IntToIntPtrZeroNil, 0, 5)
+	checkParity(t, c, Float64ToStringPtrZeroNil, 0.0, math.Copysign(0, -1), 2.5)
+}
+
+func TestParityCollectErrors(t *testing.T) {
+	// the float format is kept from the previous directives
+	c := rprim.NewConfig().SetFlags(rprim.COP_COLLECT_ERRORS)
+	c.FloatFormat = "%.2f"
+
+	checkParity(t, c, UserRowToUserCollect,
+		UserRow{},
+		UserRow{ID: "1", Name: ptr("Mary"), Address: &AddressRow{Number: "3"}},
+		UserRow{ID: "x", Address: &AddressRow{Number: "y"}},
+	)
+}
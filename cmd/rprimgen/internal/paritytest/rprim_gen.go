@@ -0,0 +1,456 @@
+// Code generated by rprimgen. DO NOT EDIT.
+
+package paritytest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/RangelReale/rprim"
+)
+
+// IntToString converts int to string.
+func IntToString(src int) (string, error) {
+	r := strconv.FormatInt(int64(src), 10)
+	return r, nil
+}
+
+// Int64PtrToInt8 converts *int64 to int8.
+func Int64PtrToInt8(src *int64) (int8, error) {
+	if src == nil {
+		return 0, errors.New("Copying nil to zero value not allowed")
+	}
+	v := *src
+	r := int8(v)
+	return r, nil
+}
+
+// Uint32ToFloat32 converts uint32 to float32.
+func Uint32ToFloat32(src uint32) (float32, error) {
+	r := float32(float64(src))
+	return r, nil
+}
+
+// Float64ToInt16 converts float64 to int16.
+func Float64ToInt16(src float64) (int16, error) {
+	r := int16(int64(math.Trunc(src)))
+	return r, nil
+}
+
+// Float64ToUint8 converts float64 to uint8.
+func Float64ToUint8(src float64) (uint8, error) {
+	r := uint8(uint64(math.Trunc(src)))
+	return r, nil
+}
+
+// Float32ToFloat64PtrPtr converts float32 to **float64.
+func Float32ToFloat64PtrPtr(src float32) (**float64, error) {
+	r := float64(src)
+	p1 := &r
+	p2 := &p1
+	return p2, nil
+}
+
+// Float64ToString converts float64 to string.
+func Float64ToString(src float64) (string, error) {
+	r := fmt.Sprintf("%f", src)
+	return r, nil
+}
+
+// Complex128ToString converts complex128 to string.
+func Complex128ToString(src complex128) (string, error) {
+	r := fmt.Sprintf("%g", src)
+	return r, nil
+}
+
+// Complex128ToComplex64 converts complex128 to complex64.
+func Complex128ToComplex64(src complex128) (complex64, error) {
+	r := complex64(src)
+	return r, nil
+}
+
+// StringToInt converts string to int.
+func StringToInt(src string) (int, error) {
+	var r int
+	if i, err := strconv.ParseInt(src, 10, 64); err == nil {
+		r = int(i)
+	} else {
+		return 0, fmt.Errorf("Error converting string to int: %w", err)
+	}
+	return r, nil
+}
+
+// StringToUint16 converts string to uint16.
+func StringToUint16(src string) (uint16, error) {
+	var r uint16
+	if i, err := strconv.ParseUint(src, 10, 64); err == nil {
+		r = uint16(i)
+	} else {
+		return 0, fmt.Errorf("Error converting string to uint: %w", err)
+	}
+	return r, nil
+}
+
+// StringToFloat32Ptr converts string to *float32.
+func StringToFloat32Ptr(src string) (*float32, error) {
+	var cv float64
+	if _, err := fmt.Sscanf(src, "%f", &cv); err != nil {
+		return nil, fmt.Errorf("Error converting string to float: %v", err)
+	}
+	r := float32(cv)
+	p1 := &r
+	return p1, nil
+}
+
+// StringToComplex64 converts string to complex64.
+func StringToComplex64(src string) (complex64, error) {
+	var cv complex128
+	if _, err := fmt.Sscanf(src, "%g", &cv); err != nil {
+		return 0, fmt.Errorf("Error converting string to complex: %v", err)
+	}
+	r := complex64(cv)
+	return r, nil
+}
+
+// CelsiusToLabel converts Celsius to Label.
+func CelsiusToLabel(src Celsius) (Label, error) {
+	r := Label(fmt.Sprintf("%f", float64(src)))
+	return r, nil
+}
+
+// LabelPtrToCelsius converts *Label to Celsius.
+func LabelPtrToCelsius(src *Label) (Celsius, error) {
+	if src == nil {
+		return 0, errors.New("Copying nil to zero value not allowed")
+	}
+	v := *src
+	var cv float64
+	if _, err := fmt.Sscanf(string(v), "%f", &cv); err != nil {
+		return 0, fmt.Errorf("Error converting string to float: %v", err)
+	}
+	r := Celsius(cv)
+	return r, nil
+}
+
+// IntPtrToInt converts *int to int.
+func IntPtrToInt(src *int) (int, error) {
+	if src == nil {
+		return 0, errors.New("Copying nil to zero value not allowed")
+	}
+	v := *src
+	r := v
+	return r, nil
+}
+
+// UserRowToUser converts *UserRow to User.
+func UserRowToUser(src *UserRow) (User, error) {
+	if src == nil {
+		return User{}, errors.New("Copying nil to zero value not allowed")
+	}
+	v := *src
+	var r User
+	if cv, err := rprimgenConvert1(v.ID); err != nil {
+		return User{}, rprimgenWithPath(".ID", err)
+	} else {
+		r.ID = cv
+	}
+	if cv, err := rprimgenConvert2(v.Name); err != nil {
+		return User{}, rprimgenWithPath(".Name", err)
+	} else {
+		r.Name = cv
+	}
+	if cv, err := rprimgenConvert3(v.Score); err != nil {
+		return User{}, rprimgenWithPath(".Score", err)
+	} else {
+		r.Score = cv
+	}
+	r.Active = v.Active
+	if cv, err := rprimgenConvert4(v.Address); err != nil {
+		return User{}, rprimgenWithPath(".Address", err)
+	} else {
+		r.Address = cv
+	}
+	return r, nil
+}
+
+// IntPtrPtrToIntZero converts **int to int.
+func IntPtrPtrToIntZero(src **int) (int, error) {
+	if src == nil {
+		return 0, nil
+	}
+	if *src == nil {
+		return 0, rprim.ErrNilPointerChain
+	}
+	v := **src
+	r := v
+	return r, nil
+}
+
+// StringToInt32Lenient converts string to int32.
+func StringToInt32Lenient(src string) (int32, error) {
+	var r int32
+	if i, err := strconv.ParseInt(src, 10, 64); err == nil {
+		r = int32(i)
+	} else if errors.Is(err, strconv.ErrRange) {
+		return 0, fmt.Errorf("Error converting string to int: %w", err)
+	} else if f, err := strconv.ParseFloat(src, 64); err == nil {
+		r = int32(int64(math.Trunc(f)))
+	} else {
+		return 0, fmt.Errorf("Error converting string to int: %v", err)
+	}
+	return r, nil
+}
+
+// StringToUint64Lenient converts string to uint64.
+func StringToUint64Lenient(src string) (uint64, error) {
+	var r uint64
+	if i, err := strconv.ParseUint(src, 10, 64); err == nil {
+		r = i
+	} else if errors.Is(err, strconv.ErrRange) {
+		return 0, fmt.Errorf("Error converting string to uint: %w", err)
+	} else if f, err := strconv.ParseFloat(src, 64); err == nil {
+		r = uint64(math.Trunc(f))
+	} else {
+		return 0, fmt.Errorf("Error converting string to uint: %v", err)
+	}
+	return r, nil
+}
+
+// StringToBytes converts string to []byte.
+func StringToBytes(src string) ([]byte, error) {
+	r := []byte(src)
+	return r, nil
+}
+
+// StringToRunes converts *string to []rune.
+func StringToRunes(src *string) ([]rune, error) {
+	if src == nil {
+		return nil, nil
+	}
+	v := *src
+	r := []rune(v)
+	return r, nil
+}
+
+// RunesToString converts []rune to string.
+func RunesToString(src []rune) (string, error) {
+	r := string(src)
+	return r, nil
+}
+
+// Float32ToStringFormat converts float32 to string.
+func Float32ToStringFormat(src float32) (string, error) {
+	r := fmt.Sprintf("%.2f", float64(src))
+	return r, nil
+}
+
+// StringToFloat64Format converts string to float64.
+func StringToFloat64Format(src string) (float64, error) {
+	var cv float64
+	if _, err := fmt.Sscanf(src, "%.2f", &cv); err != nil {
+		return 0, fmt.Errorf("Error converting string to float: %v", err)
+	}
+	r := cv
+	return r, nil
+}
+
+// IntToIntPtrZeroNil converts int to *int.
+func IntToIntPtrZeroNil(src int) (*int, error) {
+	if src == 0 {
+		return nil, nil
+	}
+	r := src
+	p1 := &r
+	return p1, nil
+}
+
+// Float64ToStringPtrZeroNil converts float64 to *string.
+func Float64ToStringPtrZeroNil(src float64) (*string, error) {
+	if src == 0 {
+		return nil, nil
+	}
+	r := fmt.Sprintf("%.2f", src)
+	p1 := &r
+	return p1, nil
+}
+
+// UserRowToUserCollect converts UserRow to *User.
+func UserRowToUserCollect(src UserRow) (*User, error) {
+	var r User
+	var errs rprim.Errors
+	if cv, err := rprimgenConvert5(src.ID); err != nil {
+		errs = rprimgenAppendErrors(errs, rprimgenWithPath(".ID", err))
+	} else {
+		r.ID = cv
+	}
+	if cv, err := rprimgenConvert6(src.Name); err != nil {
+		errs = rprimgenAppendErrors(errs, rprimgenWithPath(".Name", err))
+	} else {
+		r.Name = cv
+	}
+	if cv, err := rprimgenConvert7(src.Score); err != nil {
+		errs = rprimgenAppendErrors(errs, rprimgenWithPath(".Score", err))
+	} else {
+		r.Score = cv
+	}
+	r.Active = src.Active
+	if cv, err := rprimgenConvert8(src.Address); err != nil {
+		errs = rprimgenAppendErrors(errs, rprimgenWithPath(".Address", err))
+	} else {
+		r.Address = cv
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	p1 := &r
+	return p1, nil
+}
+
+func rprimgenConvert1(src string) (int64, error) {
+	var r int64
+	if i, err := strconv.ParseInt(src, 10, 64); err == nil {
+		r = i
+	} else {
+		return 0, fmt.Errorf("Error converting string to int: %w", err)
+	}
+	return r, nil
+}
+
+func rprimgenConvert2(src *string) (string, error) {
+	if src == nil {
+		return "", errors.New("Copying nil to zero value not allowed")
+	}
+	v := *src
+	r := v
+	return r, nil
+}
+
+func rprimgenConvert3(src float64) (*string, error) {
+	r := fmt.Sprintf("%f", src)
+	p1 := &r
+	return p1, nil
+}
+
+func rprimgenConvert4(src *AddressRow) (Address, error) {
+	if src == nil {
+		return Address{}, errors.New("Copying nil to zero value not allowed")
+	}
+	v := *src
+	var r Address
+	if cv, err := rprimgenConvert9(v.Street); err != nil {
+		return Address{}, rprimgenWithPath(".Street", err)
+	} else {
+		r.Street = cv
+	}
+	if cv, err := rprimgenConvert10(v.Number); err != nil {
+		return Address{}, rprimgenWithPath(".Number", err)
+	} else {
+		r.Number = cv
+	}
+	return r, nil
+}
+
+func rprimgenConvert5(src string) (int64, error) {
+	var r int64
+	if i, err := strconv.ParseInt(src, 10, 64); err == nil {
+		r = i
+	} else {
+		return 0, fmt.Errorf("Error converting string to int: %w", err)
+	}
+	return r, nil
+}
+
+func rprimgenConvert6(src *string) (string, error) {
+	if src == nil {
+		return "", errors.New("Copying nil to zero value not allowed")
+	}
+	v := *src
+	r := v
+	return r, nil
+}
+
+func rprimgenConvert7(src float64) (*string, error) {
+	r := fmt.Sprintf("%.2f", src)
+	p1 := &r
+	return p1, nil
+}
+
+func rprimgenConvert8(src *AddressRow) (Address, error) {
+	if src == nil {
+		return Address{}, errors.New("Copying nil to zero value not allowed")
+	}
+	v := *src
+	var r Address
+	var errs rprim.Errors
+	if cv, err := rprimgenConvert11(v.Street); err != nil {
+		errs = rprimgenAppendErrors(errs, rprimgenWithPath(".Street", err))
+	} else {
+		r.Street = cv
+	}
+	if cv, err := rprimgenConvert12(v.Number); err != nil {
+		errs = rprimgenAppendErrors(errs, rprimgenWithPath(".Number", err))
+	} else {
+		r.Number = cv
+	}
+	if len(errs) > 0 {
+		return Address{}, errs
+	}
+	return r, nil
+}
+
+func rprimgenConvert9(src string) (string, error) {
+	r := src
+	return r, nil
+}
+
+func rprimgenConvert10(src string) (*int, error) {
+	var r int
+	if i, err := strconv.ParseInt(src, 10, 64); err == nil {
+		r = int(i)
+	} else {
+		return nil, fmt.Errorf("Error converting string to int: %w", err)
+	}
+	p1 := &r
+	return p1, nil
+}
+
+func rprimgenConvert11(src string) (string, error) {
+	r := src
+	return r, nil
+}
+
+func rprimgenConvert12(src string) (*int, error) {
+	var r int
+	if i, err := strconv.ParseInt(src, 10, 64); err == nil {
+		r = int(i)
+	} else {
+		return nil, fmt.Errorf("Error converting string to int: %w", err)
+	}
+	p1 := &r
+	return p1, nil
+}
+
+// rprimgenWithPath adds the path prefix to the error, or to all errors of a list.
+func rprimgenWithPath(prefix string, err error) error {
+	switch perr := err.(type) {
+	case *rprim.PathError:
+		return &rprim.PathError{Path: prefix + perr.Path, Err: perr.Err}
+	case rprim.Errors:
+		ret := make(rprim.Errors, len(perr))
+		for i, e := range perr {
+			ret[i] = rprimgenWithPath(prefix, e)
+		}
+		return ret
+	}
+	return &rprim.PathError{Path: prefix, Err: err}
+}
+
+// rprimgenAppendErrors appends the error to the list, flattening lists.
+func rprimgenAppendErrors(errs rprim.Errors, err error) rprim.Errors {
+	if el, ok := err.(rprim.Errors); ok {
+		return append(errs, el...)
+	}
+	return append(errs, err)
+}
@@ -0,0 +1,73 @@
+// Package paritytest contains conversions generated by rprimgen, tested against the reflective
+// conversions of rprim.
+package paritytest
+
+//go:generate go run ../..
+
+type Celsius float64
+
+type Label string
+
+type AddressRow struct {
+	Street string
+	Number string
+}
+
+type Address struct {
+	Street string
+	Number *int
+}
+
+type UserRow struct {
+	ID      string
+	Name    *string
+	Score   float64
+	Active  bool
+	Address *AddressRow
+	Comment string
+	secret  string
+}
+
+type User struct {
+	ID      int64
+	Name    string
+	Score   *string
+	Active  bool
+	Address Address
+	Extra   int
+	secret  string
+}
+
+//rprimgen:convert IntToString int string
+//rprimgen:convert Int64PtrToInt8 *int64 int8
+//rprimgen:convert Uint32ToFloat32 uint32 float32
+//rprimgen:convert Float64ToInt16 float64 int16
+//rprimgen:convert Float64ToUint8 float64 uint8
+//rprimgen:convert Float32ToFloat64PtrPtr float32 **float64
+//rprimgen:convert Float64ToString float64 string
+//rprimgen:convert Complex128ToString complex128 string
+//rprimgen:convert Complex128ToComplex64 complex128 complex64
+//rprimgen:convert StringToInt string int
+//rprimgen:convert StringToUint16 string uint16
+//rprimgen:convert StringToFloat32Ptr string *float32
+//rprimgen:convert StringToComplex64 string complex64
+//rprimgen:convert CelsiusToLabel Celsius Label
+//rprimgen:convert LabelPtrToCelsius *Label Celsius
+//rprimgen:convert IntPtrToInt *int int
+//rprimgen:convert UserRowToUser *UserRow User
+
+//rprimgen:flags COP_ALLOW_NIL_TO_ZERO_VALUE|COP_ALLOW_STRING_TO_SLICE|COP_ALLOW_SLICE_TO_SRING|COP_LENIENT_NUMERIC_STRING|COP_ZERO_TO_NIL
+//rprimgen:floatformat %.2f
+//rprimgen:convert IntPtrPtrToIntZero **int int
+//rprimgen:convert StringToInt32Lenient string int32
+//rprimgen:convert StringToUint64Lenient string uint64
+//rprimgen:convert StringToBytes string []byte
+//rprimgen:convert StringToRunes *string []rune
+//rprimgen:convert RunesToString []rune string
+//rprimgen:convert Float32ToStringFormat float32 string
+//rprimgen:convert StringToFloat64Format string float64
+//rprimgen:convert IntToIntPtrZeroNil int *int
+//rprimgen:convert Float64ToStringPtrZeroNil float64 *string
+
+//rprimgen:flags COP_COLLECT_ERRORS
+//rprimgen:convert UserRowToUserCollect UserRow *User
@@ -0,0 +1,83 @@
+/*
+Command rprimgen generates reflection-free conversion functions, with the same semantics as the
+rprim.Config.ConvertOpType path: pointer indirections in either side, the nil rules, flags and float
+and complex formats.
+
+The conversions are declared using directive comments in the package source files, or in a configuration
+file with one command per line. Options apply to all the conversions that follow them in the same file.
+
+	//rprimgen:flags COP_ALLOW_NIL_TO_ZERO_VALUE|COP_ZERO_TO_NIL
+	//rprimgen:floatformat %.2f
+	//rprimgen:convert UserRowToUser *UserRow User
+
+Each conversion generates a function with the signature:
+
+	func UserRowToUser(src *UserRow) (User, error)
+
+Supported types are the primitive types (int, uint, float, complex and string kinds), []byte and []rune,
+and types declared in the package with one of these as the underlying type, or structs with fields of
+these types (including bool fields with the same type in both sides), all with any number of pointer
+indirections. Structs are converted field by field as rprim does, using the exported fields with the same
+name. Embedded fields, single-field wrapper structs, interfaces and types from other packages are not
+supported, and return an error when generating.
+
+The rounding, overflow, precision and NaN options of rprim.Config are always the default ones.
+
+Usage:
+
+	rprimgen [-dir dir] [-config file] [-output file]
+
+It is usually run with go generate:
+
+	//go:generate go run github.com/RangelReale/rprim/cmd/rprimgen
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "package directory")
+	config := flag.String("config", "", "configuration file, instead of the directive comments")
+	output := flag.String("output", "rprim_gen.go", "output file name, relative to the package directory")
+	flag.Parse()
+
+	if err := run(*dir, *config, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "rprimgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, config, output string) error {
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(dir, output)
+	}
+
+	src, err := generateDir(dir, config, output)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, src, 0o644)
+}
+
+// Generates the conversions of the package in dir, reading the commands from the config file if not empty,
+// or else from the directive comments.
+func generateDir(dir, config, output string) ([]byte, error) {
+	g := newGenerator()
+	if err := g.parseDir(dir, output, config == ""); err != nil {
+		return nil, err
+	}
+	if config != "" {
+		if err := g.parseConfig(config); err != nil {
+			return nil, err
+		}
+	}
+	if len(g.conversions) == 0 {
+		return nil, fmt.Errorf("No conversions declared in %s", dir)
+	}
+	return g.generate()
+}
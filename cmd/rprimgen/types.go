@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Type resolved from a Go type expression.
+type genType struct {
+	// source of the full type, with pointers
+	expr string
+	// source of the type without pointers
+	base string
+	// number of pointer indirections
+	ptr int
+	// kind of the underlying type
+	kind reflect.Kind
+	// element kind of []byte and []rune
+	elem reflect.Kind
+	// struct declaration, if kind is reflect.Struct
+	structType *ast.StructType
+}
+
+// Field of a struct type.
+type genField struct {
+	name     string
+	typ      ast.Expr
+	tag      reflect.StructTag
+	embedded bool
+}
+
+var builtinKinds = map[string]reflect.Kind{
+	"bool":       reflect.Bool,
+	"int":        reflect.Int,
+	"int8":       reflect.Int8,
+	"int16":      reflect.Int16,
+	"int32":      reflect.Int32,
+	"rune":       reflect.Int32,
+	"int64":      reflect.Int64,
+	"uint":       reflect.Uint,
+	"uint8":      reflect.Uint8,
+	"byte":       reflect.Uint8,
+	"uint16":     reflect.Uint16,
+	"uint32":     reflect.Uint32,
+	"uint64":     reflect.Uint64,
+	"uintptr":    reflect.Uintptr,
+	"float32":    reflect.Float32,
+	"float64":    reflect.Float64,
+	"complex64":  reflect.Complex64,
+	"complex128": reflect.Complex128,
+	"string":     reflect.String,
+}
+
+// Resolves the type expression, using the types declared in the package.
+func (g *generator) resolve(expr ast.Expr) (*genType, error) {
+	ptr := 0
+	for {
+		star, ok := expr.(*ast.StarExpr)
+		if !ok {
+			break
+		}
+		ptr++
+		expr = star.X
+	}
+
+	t := &genType{
+		base: exprString(expr),
+		ptr:  ptr,
+	}
+	t.expr = strings.Repeat("*", ptr) + t.base
+
+	underlying := expr
+	for depth := 0; ; depth++ {
+		if depth > 100 {
+			return nil, fmt.Errorf("Type %s is recursive", t.base)
+		}
+		ident, ok := underlying.(*ast.Ident)
+		if !ok {
+			break
+		}
+		if decl, ok := g.types[ident.Name]; ok {
+			underlying = decl
+			continue
+		}
+		kind, ok := builtinKinds[ident.Name]
+		if !ok {
+			return nil, fmt.Errorf("Type %s is not supported", t.base)
+		}
+		t.kind = kind
+		return t, nil
+	}
+
+	switch ut := underlying.(type) {
+	case *ast.StructType:
+		if _, ok := expr.(*ast.Ident); !ok {
+			return nil, fmt.Errorf("Anonymous struct %s is not supported", t.base)
+		}
+		t.kind = reflect.Struct
+		t.structType = ut
+		return t, nil
+	case *ast.ArrayType:
+		if _, ok := expr.(*ast.ArrayType); ut.Len == nil && ok {
+			if elt, ok := ut.Elt.(*ast.Ident); ok {
+				if k := builtinKinds[elt.Name]; k == reflect.Uint8 || k == reflect.Int32 {
+					t.kind = reflect.Slice
+					t.elem = k
+					return t, nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("Type %s is not supported", t.base)
+}
+
+// Returns the fields of the struct type.
+func (t *genType) fields() []genField {
+	var ret []genField
+	for _, f := range t.structType.Fields.List {
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			if s, err := strconv.Unquote(f.Tag.Value); err == nil {
+				tag = reflect.StructTag(s)
+			}
+		}
+		if len(f.Names) == 0 {
+			name := exprString(f.Type)
+			name = name[strings.LastIndexAny(name, "*.")+1:]
+			ret = append(ret, genField{name: name, typ: f.Type, tag: tag, embedded: true})
+			continue
+		}
+		for _, n := range f.Names {
+			ret = append(ret, genField{name: n.Name, typ: f.Type, tag: tag})
+		}
+	}
+	return ret
+}
+
+// Checks if the struct is a single-field wrapper, with the same rules as rprim.WrapperField.
+func (g *generator) isWrapper(t *genType) bool {
+	exported := 0
+	var field genField
+	for _, f := range t.fields() {
+		if !ast.IsExported(f.name) {
+			continue
+		}
+		if tag, ok := f.tag.Lookup("rprim"); ok {
+			for _, opt := range strings.Split(tag, ",")[1:] {
+				if opt == "value" {
+					return true
+				}
+			}
+		}
+		exported++
+		field = f
+	}
+	if exported != 1 {
+		return false
+	}
+	ft, err := g.resolve(field.typ)
+	return err == nil && kindIsSimpleValue(ft.kind)
+}
+
+func kindIsSimpleValue(k reflect.Kind) bool {
+	return k != reflect.Bool && k != reflect.Struct && k != reflect.Slice
+}
+
+func kindIsSigned(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func kindIsUnsigned(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func kindIsFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func kindIsComplex(k reflect.Kind) bool {
+	return k == reflect.Complex64 || k == reflect.Complex128
+}
+
+// Returns the zero value of the type as Go source.
+func (t *genType) zero() string {
+	if t.ptr > 0 {
+		return "nil"
+	}
+	switch {
+	case t.kind == reflect.String:
+		return `""`
+	case t.kind == reflect.Bool:
+		return "false"
+	case t.kind == reflect.Slice:
+		return "nil"
+	case t.kind == reflect.Struct:
+		return t.base + "{}"
+	}
+	return "0"
+}
+
+// Returns the Go source that checks if the simple value v is zero, with the same rules as reflect.Value.IsZero.
+func (t *genType) isZero(v string) string {
+	return fmt.Sprintf("%s == %s", v, t.zero())
+}
+
+func exprString(expr ast.Expr) string {
+	var b bytes.Buffer
+	printer.Fprint(&b, token.NewFileSet(), expr)
+	return b.String()
+}
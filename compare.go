@@ -0,0 +1,308 @@
+package rprim
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// How strings are compared with numbers.
+type StringNumberPolicy int
+
+const (
+	// Strings that are valid numbers are compared as numbers, with other numbers and with numeric
+	// strings, so "1" and "1.0" are equal. Other strings are different from all numbers and cannot be
+	// ordered with them
+	STRING_NUMBER_PARSE StringNumberPolicy = iota
+	// Strings are always compared as strings, and are different from numbers
+	STRING_NUMBER_NEVER
+)
+
+// Optional parameters for value comparison.
+type CompareOptions struct {
+	// How strings are compared with numbers.
+	StringNumber StringNumberPolicy
+}
+
+// The values cannot be ordered, like a bool and a number, or complex numbers with imaginary parts.
+var ErrNotOrdered = errors.New("Values cannot be ordered")
+
+type compareClass int
+
+const (
+	compareNil compareClass = iota
+	compareBool
+	compareNumber
+	compareString
+	compareOther
+)
+
+// A value normalized for comparison.
+// Numbers are converted to an exact big.Rat, except NaN and infinities which are kept in special.
+// Complex numbers also store their imaginary part.
+type compareOperand struct {
+	class   compareClass
+	value   reflect.Value
+	num     *big.Rat
+	special float64
+	imag    float64
+}
+
+var typeBigRatPtr = reflect.PtrTo(typeBigRat)
+
+// Returns the value normalized for comparison, after pointer dereferences and unwrapping database/sql
+// nullable wrappers. Numbers are converted to big.Rat using the conversion rules of the Config.
+func (c *Config) compareValue(v interface{}, opts *CompareOptions) (compareOperand, error) {
+	if v == nil {
+		return compareOperand{class: compareNil}, nil
+	}
+	uv, err := SafeUnderliningValue(reflect.ValueOf(v), 0)
+	if err != nil {
+		return compareOperand{}, err
+	}
+	if (uv.Kind() == reflect.Ptr || uv.Kind() == reflect.Interface) && uv.IsNil() {
+		return compareOperand{class: compareNil}, nil
+	}
+	if IsSQLNullType(uv.Type()) {
+		if !uv.Field(1).Bool() {
+			return compareOperand{class: compareNil}, nil
+		}
+		return c.compareValue(uv.Field(0).Interface(), opts)
+	}
+
+	op := compareOperand{value: uv}
+	switch {
+	case uv.Kind() == reflect.Bool:
+		op.class = compareBool
+	case uv.Kind() == reflect.String:
+		op.class = compareString
+		if opts.StringNumber == STRING_NUMBER_PARSE {
+			if num, err := c.compareRat(uv); err == nil {
+				op.class = compareNumber
+				op.num = num
+			}
+		}
+	case uv.Kind() == reflect.Float32 || uv.Kind() == reflect.Float64:
+		op.class = compareNumber
+		err = op.setFloat(c, uv, uv.Float())
+	case uv.Kind() == reflect.Complex64 || uv.Kind() == reflect.Complex128:
+		op.class = compareNumber
+		err = op.setFloat(c, reflect.ValueOf(real(uv.Complex())), real(uv.Complex()))
+		op.imag = imag(uv.Complex())
+	case kindIsInteger(uv.Kind()) || IsBigType(uv.Type()):
+		op.class = compareNumber
+		if uv.Type() == typeBigFloat && bigPointer(uv).(*big.Float).IsInf() {
+			op.special = math.Inf(bigPointer(uv).(*big.Float).Sign())
+		} else {
+			op.num, err = c.compareRat(uv)
+		}
+	default:
+		op.class = compareOther
+	}
+	return op, err
+}
+
+// Sets the real part of the number from the float value, keeping NaN and infinities as special values.
+func (op *compareOperand) setFloat(c *Config, v reflect.Value, f float64) (err error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		op.special = f
+		return nil
+	}
+	op.num, err = c.compareRat(v)
+	return err
+}
+
+// Returns the value as an exact big.Rat.
+func (c *Config) compareRat(v reflect.Value) (*big.Rat, error) {
+	cv, err := c.Convert(v, typeBigRatPtr)
+	if err != nil {
+		return nil, err
+	}
+	return cv.Interface().(*big.Rat), nil
+}
+
+// Compares the real parts of the numbers. Returns an error if any of them is NaN.
+func compareReal(a, b compareOperand) (int, error) {
+	if math.IsNaN(a.special) || math.IsNaN(b.special) {
+		return 0, ErrNaN
+	}
+	if a.num != nil && b.num != nil {
+		return a.num.Cmp(b.num), nil
+	}
+	// at least one is infinite
+	af, bf := a.special, b.special
+	if a.num != nil {
+		af = 0
+	}
+	if b.num != nil {
+		bf = 0
+	}
+	switch {
+	case af < bf:
+		return -1, nil
+	case af > bf:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Checks if the values are semantically equal, converting numbers of different types and numeric strings
+// (depending on the options) to an exact big.Rat with the conversion rules of the Config, so 1, int8(1),
+// 1.0, "1", "1.0" and a *int pointing to 1 are all equal. Pointers are dereferenced and nil is only equal to nil.
+// Values that are not primitives are compared with reflect.DeepEqual.
+func (c *Config) Equal(a, b interface{}, opts *CompareOptions) bool {
+	if opts == nil {
+		opts = &CompareOptions{}
+	}
+	oa, err := c.compareValue(a, opts)
+	if err != nil {
+		return false
+	}
+	ob, err := c.compareValue(b, opts)
+	if err != nil {
+		return false
+	}
+	if oa.class != ob.class {
+		return false
+	}
+
+	switch oa.class {
+	case compareNil:
+		return true
+	case compareBool:
+		return oa.value.Bool() == ob.value.Bool()
+	case compareString:
+		return oa.value.String() == ob.value.String()
+	case compareNumber:
+		cmp, err := compareReal(oa, ob)
+		return err == nil && cmp == 0 && oa.imag == ob.imag
+	}
+	return reflect.DeepEqual(oa.value.Interface(), ob.value.Interface())
+}
+
+// Compares the values, returning -1, 0 or 1 if a is less than, equal to or greater than b, using the same
+// conversion rules as Equal. Nil is less than all other values, and false is less than true.
+// Returns ErrNotOrdered if the values have different kinds that cannot be ordered, or are complex numbers
+// with imaginary parts, and ErrNaN if any of them is NaN.
+func (c *Config) Compare(a, b interface{}, opts *CompareOptions) (int, error) {
+	if opts == nil {
+		opts = &CompareOptions{}
+	}
+	oa, err := c.compareValue(a, opts)
+	if err != nil {
+		return 0, err
+	}
+	ob, err := c.compareValue(b, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	if oa.class == compareNil || ob.class == compareNil {
+		switch {
+		case oa.class == ob.class:
+			return 0, nil
+		case oa.class == compareNil:
+			return -1, nil
+		}
+		return 1, nil
+	}
+	if oa.class != ob.class {
+		return 0, fmt.Errorf("Error comparing %s and %s: %w", oa.value.Type().String(), ob.value.Type().String(), ErrNotOrdered)
+	}
+
+	switch oa.class {
+	case compareBool:
+		ba, bb := oa.value.Bool(), ob.value.Bool()
+		switch {
+		case ba == bb:
+			return 0, nil
+		case bb:
+			return -1, nil
+		}
+		return 1, nil
+	case compareString:
+		return strings.Compare(oa.value.String(), ob.value.String()), nil
+	case compareNumber:
+		if oa.imag != 0 || ob.imag != 0 {
+			if c.Equal(a, b, opts) {
+				return 0, nil
+			}
+			return 0, fmt.Errorf("Error comparing complex numbers: %w", ErrNotOrdered)
+		}
+		return compareReal(oa, ob)
+	}
+	if reflect.DeepEqual(oa.value.Interface(), ob.value.Interface()) {
+		return 0, nil
+	}
+	return 0, fmt.Errorf("Error comparing %s and %s: %w", oa.value.Type().String(), ob.value.Type().String(), ErrNotOrdered)
+}
+
+// Key of numbers that cannot be represented as int64, uint64 or float64.
+type numberKey string
+
+// Key of values that are not comparable, like slices and maps.
+type compositeKey string
+
+// Key of values that cannot be walked, like pointer cycles, which are only equal to themselves.
+type cycleKey struct {
+	v interface{}
+}
+
+// Returns a comparable key for the value, which can be used in maps to group values. Values that are
+// Equal return the same key, except NaN, which is never equal to itself.
+// Integral numbers return an int64 (or uint64 if too large), other real numbers a float64 if exactly
+// representable, and complex numbers with imaginary parts a complex128. Strings that are valid numbers
+// return the number key depending on the options.
+func (c *Config) Key(v interface{}, opts *CompareOptions) interface{} {
+	if opts == nil {
+		opts = &CompareOptions{}
+	}
+	op, err := c.compareValue(v, opts)
+	if err != nil {
+		// only pointers can fail to be walked, which are comparable
+		return cycleKey{v}
+	}
+
+	switch op.class {
+	case compareNil:
+		return nil
+	case compareBool:
+		return op.value.Bool()
+	case compareString:
+		return op.value.String()
+	case compareNumber:
+		return numberOperandKey(op)
+	}
+	if op.value.Type().Comparable() {
+		return op.value.Interface()
+	}
+	return compositeKey(fmt.Sprintf("%#v", op.value.Interface()))
+}
+
+func numberOperandKey(op compareOperand) interface{} {
+	if op.num == nil {
+		if op.imag != 0 {
+			return complex(op.special, op.imag)
+		}
+		return op.special
+	}
+	if op.imag != 0 {
+		f, _ := op.num.Float64()
+		return complex(f, op.imag)
+	}
+	if op.num.IsInt() {
+		n := op.num.Num()
+		if n.IsInt64() {
+			return n.Int64()
+		}
+		if n.IsUint64() {
+			return n.Uint64()
+		}
+	} else if f, exact := op.num.Float64(); exact {
+		return f
+	}
+	return numberKey(op.num.RatString())
+}
@@ -0,0 +1,152 @@
+package rprim
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	one := 1
+	pone := &one
+	equal := []interface{}{1, int8(1), uint64(1), 1.0, float32(1), complex(1, 0), "1", " 1.0 ", &pone,
+		big.NewInt(1), big.NewRat(2, 2), sql.NullInt32{Int32: 1, Valid: true}}
+	for _, a := range equal {
+		for _, b := range equal {
+			if !Equal(a, b, nil) {
+				t.Fatalf("%#v and %#v should be equal", a, b)
+			}
+			if Key(a) != Key(b) {
+				t.Fatalf("%#v and %#v should have the same key, got %#v and %#v", a, b, Key(a), Key(b))
+			}
+		}
+	}
+
+	different := [][2]interface{}{
+		{1, 2},
+		{1, "x"},
+		{1, true},
+		{"1", "x"},
+		{nil, 0},
+		{sql.NullInt64{}, 0},
+		{uint64(math.MaxUint64), int64(-1)},
+		{math.NaN(), math.NaN()},
+		{complex(1, 1), 1},
+		{[]int{1}, []int{2}},
+	}
+	for _, test := range different {
+		if Equal(test[0], test[1], nil) {
+			t.Fatalf("%#v and %#v should not be equal", test[0], test[1])
+		}
+	}
+
+	never := &CompareOptions{StringNumber: STRING_NUMBER_NEVER}
+	if Equal(1, "1", never) {
+		t.Fatal("1 and \"1\" should not be equal when strings are never numbers")
+	}
+	if Equal("1", "1.0", never) {
+		t.Fatal("\"1\" and \"1.0\" should not be equal when strings are never numbers")
+	}
+	if !Equal(nil, (*int)(nil), nil) || !Equal(sql.NullString{}, nil, nil) {
+		t.Fatal("nil values should be equal")
+	}
+	if !Equal([]int{1}, []int{1}, nil) {
+		t.Fatal("slices should be deeply equal")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b     interface{}
+		expected int
+	}{
+		{1, 2, -1},
+		{int8(-1), uint64(math.MaxUint64), -1},
+		{uint64(math.MaxUint64), 1e19, 1},
+		{int64(math.MaxInt64), float64(math.MaxInt64), -1},
+		{"10", 9, 1},
+		{"10", "9", 1},
+		{"b", "a", 1},
+		{2.5, "2.50", 0},
+		{math.Inf(-1), int64(math.MinInt64), -1},
+		{math.Inf(1), math.Inf(1), 0},
+		{nil, -100, -1},
+		{"a", nil, 1},
+		{false, true, -1},
+		{big.NewRat(1, 3), 0.3333, 1},
+	}
+	for _, test := range tests {
+		cmp, err := Compare(test.a, test.b, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cmp != test.expected {
+			t.Fatalf("Comparing %#v and %#v expected %d, got %d", test.a, test.b, test.expected, cmp)
+		}
+	}
+
+	if _, err := Compare(1, "x", nil); !errors.Is(err, ErrNotOrdered) {
+		t.Fatalf("Expected not ordered error, got %v", err)
+	}
+	if _, err := Compare(1, true, nil); !errors.Is(err, ErrNotOrdered) {
+		t.Fatalf("Expected not ordered error, got %v", err)
+	}
+	if _, err := Compare(complex(1, 1), 1, nil); !errors.Is(err, ErrNotOrdered) {
+		t.Fatalf("Expected not ordered error, got %v", err)
+	}
+	if _, err := Compare(math.NaN(), 1, nil); !errors.Is(err, ErrNaN) {
+		t.Fatalf("Expected NaN error, got %v", err)
+	}
+	if _, err := Compare("1", 1, &CompareOptions{StringNumber: STRING_NUMBER_NEVER}); !errors.Is(err, ErrNotOrdered) {
+		t.Fatalf("Expected not ordered error, got %v", err)
+	}
+	if cmp, err := Compare("10", "9", &CompareOptions{StringNumber: STRING_NUMBER_NEVER}); err != nil || cmp != -1 {
+		t.Fatalf("Expected -1, got %d (%v)", cmp, err)
+	}
+	if _, err := Compare("10", "x", nil); !errors.Is(err, ErrNotOrdered) {
+		t.Fatalf("Expected not ordered error, got %v", err)
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		v        interface{}
+		expected interface{}
+	}{
+		{int8(3), int64(3)},
+		{3.0, int64(3)},
+		{"3", int64(3)},
+		{"x", "x"},
+		{uint64(math.MaxUint64), uint64(math.MaxUint64)},
+		{0.5, 0.5},
+		{"1/3", numberKey("1/3")},
+		{1e20, numberKey("100000000000000000000")},
+		{complex(1, 2), complex(1, 2)},
+		{math.Inf(1), math.Inf(1)},
+		{true, true},
+		{nil, nil},
+		{sql.NullString{}, nil},
+	}
+	for _, test := range tests {
+		if k := Key(test.v); k != test.expected {
+			t.Fatalf("Key of %#v expected %#v, got %#v", test.v, test.expected, k)
+		}
+	}
+
+	if Key([]int{1, 2}) != Key([]int{1, 2}) {
+		t.Fatal("Equal slices should have the same key")
+	}
+	if k := NewConfig().Key("3", &CompareOptions{StringNumber: STRING_NUMBER_NEVER}); k != "3" {
+		t.Fatalf("Key expected \"3\", got %#v", k)
+	}
+
+	// cyclic values only share a key with themselves
+	var x, y interface{}
+	x = &x
+	y = &y
+	if Key(x) != Key(x) || Key(x) == Key(y) {
+		t.Fatal("Cyclic values should have a key unique to the value")
+	}
+}
@@ -0,0 +1,259 @@
+package rprim
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Checks if the kind is a container that is converted element by element.
+func kindIsContainer(k reflect.Kind) bool {
+	return k == reflect.Slice || k == reflect.Array || k == reflect.Map || k == reflect.Struct
+}
+
+// Checks if the element type can be converted.
+func (c Config) canConvertElem(srcType, dstType reflect.Type) bool {
+	return c.canConvertElemVisiting(srcType, dstType, map[containerVisit]bool{})
+}
+
+// Same as canConvertElem, checking nested containers recursively. Container pairs being checked are
+// stored in visiting, and are assumed to be convertible if found again, as in recursive types.
+func (c Config) canConvertElemVisiting(srcType, dstType reflect.Type, visiting map[containerVisit]bool) bool {
+	us, ud := UnderliningType(srcType), UnderliningType(dstType)
+	if kindIsContainer(us.Kind()) && kindIsContainer(ud.Kind()) {
+		if isElementContainer(us) && isElementContainer(ud) {
+			return c.canConvertContainer(us, ud, visiting)
+		}
+		// big numbers, nullable and wrapper structs are only checked when converting
+		return true
+	}
+	cop, strategy, _ := c.convertOp(typeSource(srcType), dstType)
+	return cop != nil || strategy == STRATEGY_DYNAMIC || us.AssignableTo(ud)
+}
+
+// Checks if the type is converted element by element, and not by one of the special struct conversions.
+func isElementContainer(t reflect.Type) bool {
+	return t.Kind() != reflect.Struct || (!IsBigType(t) && !IsSQLNullType(t) && WrapperField(t) < 0)
+}
+
+// A pair of container types being checked.
+type containerVisit struct {
+	src, dst reflect.Type
+}
+
+// Returns the conversion function between slices, arrays, maps and structs, converting each element,
+// or nil if not supported.
+func (c Config) containerOp(srcType, dstType reflect.Type) ConvertOpFunc {
+	if !c.canConvertContainer(srcType, dstType, map[containerVisit]bool{}) {
+		return nil
+	}
+	if srcType.AssignableTo(dstType) {
+		return cvtDirectPointer
+	}
+
+	sk, dk := srcType.Kind(), dstType.Kind()
+	switch {
+	case sk == reflect.Slice || sk == reflect.Array:
+		return c.cvtSlice
+	case sk == reflect.Map:
+		return c.cvtMap
+	case srcType.ConvertibleTo(dstType):
+		// identical struct layouts also keep the unexported fields
+		return cvtDirectPointer
+	case dk == reflect.Struct:
+		return c.cvtStruct
+	}
+	return nil
+}
+
+// Checks if the container types can be converted, including the elements of nested containers.
+func (c Config) canConvertContainer(srcType, dstType reflect.Type, visiting map[containerVisit]bool) bool {
+	if srcType.AssignableTo(dstType) {
+		return true
+	}
+	visit := containerVisit{srcType, dstType}
+	if visiting[visit] {
+		return true
+	}
+	visiting[visit] = true
+	defer delete(visiting, visit)
+
+	sk, dk := srcType.Kind(), dstType.Kind()
+	switch {
+	case (sk == reflect.Slice || sk == reflect.Array) && (dk == reflect.Slice || dk == reflect.Array):
+		return c.canConvertElemVisiting(srcType.Elem(), dstType.Elem(), visiting)
+	case sk == reflect.Map && dk == reflect.Map:
+		return c.canConvertElemVisiting(srcType.Key(), dstType.Key(), visiting) &&
+			c.canConvertElemVisiting(srcType.Elem(), dstType.Elem(), visiting)
+	case sk == reflect.Struct && dk == reflect.Struct:
+		return srcType.ConvertibleTo(dstType) || c.canConvertStruct(srcType, dstType, visiting)
+	}
+	return false
+}
+
+// Checks if at least one exported field has the same name in both structs, and if all fields with the
+// same name can be converted.
+func (c Config) canConvertStruct(srcType, dstType reflect.Type, visiting map[containerVisit]bool) bool {
+	matched := false
+	for i := 0; i < dstType.NumField(); i++ {
+		df := dstType.Field(i)
+		if df.PkgPath != "" {
+			continue
+		}
+		sf, ok := srcType.FieldByName(df.Name)
+		if !ok || sf.PkgPath != "" {
+			continue
+		}
+		if !c.canConvertElemVisiting(sf.Type, df.Type, visiting) {
+			return false
+		}
+		matched = true
+	}
+	return matched
+}
+
+// Converts a single element of a container.
+func (c Config) convertElem(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	cop := c.convertOpValue(v, t)
+	if cop == nil {
+		if UnderliningValueType(v).AssignableTo(UnderliningType(t)) {
+			cop = cvtDirectPointer
+		} else {
+			return reflect.Value{}, fmt.Errorf("Invalid conversion from %s to %s", v.Type().String(), t.String())
+		}
+	}
+	return cop(v, t)
+}
+
+// Collects element errors, returning true if the conversion should continue.
+type elemErrors struct {
+	collect bool
+	errs    Errors
+}
+
+func (e *elemErrors) add(path string, err error) bool {
+	e.errs = appendErrors(e.errs, withPath(path, err))
+	return e.collect
+}
+
+func (e *elemErrors) err() error {
+	if len(e.errs) == 0 {
+		return nil
+	}
+	if !e.collect {
+		return e.errs[0]
+	}
+	return e.errs
+}
+
+// ConvertOp: slice or array -> slice or array
+func (c Config) cvtSlice(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	sv := UnderliningValue(v)
+	root, last := NewUnderliningValue(t)
+	if sv.Kind() == reflect.Slice && sv.IsNil() {
+		return root, nil
+	}
+
+	n := sv.Len()
+	if last.Kind() == reflect.Slice {
+		last.Set(reflect.MakeSlice(last.Type(), n, n))
+	} else if n > last.Len() {
+		return reflect.Value{}, fmt.Errorf("Source length %d is larger than destination array length %d", n, last.Len())
+	}
+
+	errs := &elemErrors{collect: (c.Flags & COP_COLLECT_ERRORS) == COP_COLLECT_ERRORS}
+	elemType := last.Type().Elem()
+	for i := 0; i < n; i++ {
+		cv, err := c.convertElem(sv.Index(i), elemType)
+		if err != nil {
+			if !errs.add(fmt.Sprintf("[%d]", i), err) {
+				break
+			}
+			continue
+		}
+		last.Index(i).Set(cv)
+	}
+	if err := errs.err(); err != nil {
+		return reflect.Value{}, err
+	}
+	return root, nil
+}
+
+// ConvertOp: map -> map
+func (c Config) cvtMap(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	sv := UnderliningValue(v)
+	root, last := NewUnderliningValue(t)
+	if sv.IsNil() {
+		return root, nil
+	}
+
+	last.Set(reflect.MakeMapWithSize(last.Type(), sv.Len()))
+
+	// sorted keys so errors are deterministic
+	keys := sv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+
+	errs := &elemErrors{collect: (c.Flags & COP_COLLECT_ERRORS) == COP_COLLECT_ERRORS}
+	keyType, elemType := last.Type().Key(), last.Type().Elem()
+	for _, key := range keys {
+		path := fmt.Sprintf("[%v]", key.Interface())
+		ck, err := c.convertElem(key, keyType)
+		if err != nil {
+			if !errs.add(path, err) {
+				break
+			}
+			continue
+		}
+		cv, err := c.convertElem(sv.MapIndex(key), elemType)
+		if err != nil {
+			if !errs.add(path, err) {
+				break
+			}
+			continue
+		}
+		last.SetMapIndex(ck, cv)
+	}
+	if err := errs.err(); err != nil {
+		return reflect.Value{}, err
+	}
+	return root, nil
+}
+
+// ConvertOp: struct -> struct, converting exported fields with the same name
+func (c Config) cvtStruct(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	sv := UnderliningValue(v)
+	root, last := NewUnderliningValue(t)
+
+	errs := &elemErrors{collect: (c.Flags & COP_COLLECT_ERRORS) == COP_COLLECT_ERRORS}
+	lt := last.Type()
+	for i := 0; i < lt.NumField(); i++ {
+		df := lt.Field(i)
+		if df.PkgPath != "" {
+			// unexported
+			continue
+		}
+		sf, ok := sv.Type().FieldByName(df.Name)
+		if !ok || sf.PkgPath != "" {
+			continue
+		}
+		sfv, err := sv.FieldByIndexErr(sf.Index)
+		if err != nil {
+			// nil embedded pointer
+			continue
+		}
+		cv, err := c.convertElem(sfv, df.Type)
+		if err != nil {
+			if !errs.add("."+df.Name, err) {
+				break
+			}
+			continue
+		}
+		last.Field(i).Set(cv)
+	}
+	if err := errs.err(); err != nil {
+		return reflect.Value{}, err
+	}
+	return root, nil
+}
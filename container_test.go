@@ -0,0 +1,194 @@
+package rprim
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type testContainerItemSrc struct {
+	Name  string
+	Price string
+	Tags  []string
+}
+
+type testContainerItemDst struct {
+	Name  string
+	Price float64
+	Tags  []int
+	Extra int
+}
+
+type testContainerSrc struct {
+	Items []*testContainerItemSrc
+	Count map[string]string
+}
+
+type testContainerDst struct {
+	Items []testContainerItemDst
+	Count map[string]uint
+}
+
+func TestContainerConvert(t *testing.T) {
+	src := testContainerSrc{
+		Items: []*testContainerItemSrc{
+			{Name: "a", Price: "1.5", Tags: []string{"1", "2"}},
+			{Name: "b", Price: "2", Tags: nil},
+		},
+		Count: map[string]string{"x": "10"},
+	}
+
+	cv, err := Convert(reflect.ValueOf(src), reflect.TypeOf(&testContainerDst{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := cv.Interface().(*testContainerDst)
+
+	if len(dst.Items) != 2 || dst.Items[0].Name != "a" || dst.Items[0].Price != 1.5 ||
+		len(dst.Items[0].Tags) != 2 || dst.Items[0].Tags[1] != 2 {
+		t.Fatalf("Invalid first item: %+v", dst.Items[0])
+	}
+	if dst.Items[1].Name != "b" || dst.Items[1].Price != 2 || dst.Items[1].Tags != nil {
+		t.Fatalf("Invalid second item: %+v", dst.Items[1])
+	}
+	if dst.Count["x"] != 10 {
+		t.Fatalf("Invalid map: %v", dst.Count)
+	}
+
+	arr, err := Convert(reflect.ValueOf([3]int{1, 2, 3}), reflect.TypeOf([]string{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strs := arr.Interface().([]string); len(strs) != 3 || strs[2] != "3" {
+		t.Fatalf("Invalid slice: %v", strs)
+	}
+}
+
+func TestContainerErrorPath(t *testing.T) {
+	src := testContainerSrc{
+		Items: []*testContainerItemSrc{
+			{Name: "a", Price: "1.5"},
+			{Name: "b", Price: "abc", Tags: []string{"1", "x"}},
+		},
+		Count: map[string]string{"y": "-", "z": "2"},
+	}
+	dstType := reflect.TypeOf(testContainerDst{})
+
+	_, err := Convert(reflect.ValueOf(src), dstType)
+	var perr *PathError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Expected path error, got %v", err)
+	}
+	if perr.Path != ".Items[1].Price" {
+		t.Fatalf("Invalid error path %s", perr.Path)
+	}
+
+	_, err = NewConfig().AddFlags(COP_COLLECT_ERRORS).Convert(reflect.ValueOf(src), dstType)
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("Expected error list, got %v", err)
+	}
+	var paths []string
+	for _, e := range errs {
+		if !errors.As(e, &perr) {
+			t.Fatalf("Expected path error, got %v", e)
+		}
+		paths = append(paths, perr.Path)
+	}
+	expected := []string{".Items[1].Price", ".Items[1].Tags[1]", ".Count[y]"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("Error paths should be %v, are %v", expected, paths)
+	}
+
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		t.Fatal("Error list should unwrap to the conversion errors")
+	}
+}
+
+type testContainerTime time.Time
+
+type testContainerMismatch struct {
+	Other int
+}
+
+type testContainerBadField struct {
+	Name chan int
+}
+
+func TestContainerStructMismatch(t *testing.T) {
+	srcType := reflect.TypeOf(testContainerItemSrc{})
+
+	if CanConvert(srcType, reflect.TypeOf(testContainerMismatch{})) {
+		t.Fatal("Structs without common fields should not be convertible")
+	}
+	if _, err := Convert(reflect.ValueOf(testContainerItemSrc{Name: "a"}), reflect.TypeOf(testContainerMismatch{})); err == nil {
+		t.Fatal("Expected error converting structs without common fields")
+	}
+	if CanConvert(srcType, reflect.TypeOf(testContainerBadField{})) {
+		t.Fatal("Structs with unconvertible common fields should not be convertible")
+	}
+}
+
+func TestContainerStructUnexported(t *testing.T) {
+	type unexportedA struct{ a, b int }
+	type unexportedB struct{ a, c int }
+
+	if CanConvert(reflect.TypeOf(unexportedA{}), reflect.TypeOf(unexportedB{})) {
+		t.Fatal("Structs with only unexported fields should not be convertible")
+	}
+
+	// identical layouts are converted directly, keeping the unexported fields
+	now := time.Now()
+	cv, err := Convert(reflect.ValueOf(now), reflect.TypeOf(testContainerTime{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !time.Time(cv.Interface().(testContainerTime)).Equal(now) {
+		t.Fatalf("Expected %v, got %v", now, time.Time(cv.Interface().(testContainerTime)))
+	}
+}
+
+type testContainerTree struct {
+	Name     string
+	Children []testContainerTree
+}
+
+type testContainerTreeDst struct {
+	Name     []byte
+	Children []testContainerTreeDst
+}
+
+type testContainerTreeBad struct {
+	Name     chan int
+	Children []testContainerTreeBad
+}
+
+func TestContainerNested(t *testing.T) {
+	if CanConvert(reflect.TypeOf([][]string{}), reflect.TypeOf([][]testContainerBadField{})) {
+		t.Fatal("Nested containers with unconvertible elements should not be convertible")
+	}
+	if !CanConvert(reflect.TypeOf([][]string{}), reflect.TypeOf([][]int{})) {
+		t.Fatal("Nested containers with convertible elements should be convertible")
+	}
+
+	// recursive types
+	treeType := reflect.TypeOf(testContainerTree{})
+	if CanConvert(treeType, reflect.TypeOf(testContainerTreeBad{})) {
+		t.Fatal("Recursive structs with unconvertible fields should not be convertible")
+	}
+	c := NewConfig().SetFlags(COP_ALLOW_STRING_TO_SLICE)
+	if !c.CanConvert(treeType, reflect.TypeOf(testContainerTreeDst{})) {
+		t.Fatal("Recursive structs with convertible fields should be convertible")
+	}
+	tree := testContainerTree{Name: "a", Children: []testContainerTree{{Name: "b"}}}
+	cv, err := c.Convert(reflect.ValueOf(tree), reflect.TypeOf(testContainerTreeDst{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dst := cv.Interface().(testContainerTreeDst); len(dst.Children) != 1 || string(dst.Children[0].Name) != "b" {
+		t.Fatalf("Invalid value %+v", dst)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -16,6 +17,12 @@ const (
 	COP_ALLOW_STRING_TO_SLICE = 2
 	// Whether to allow slice to string conversion ([]uint8 or []int32 only)
 	COP_ALLOW_SLICE_TO_SRING = 4
+	// Whether to allow strings with fractional values to be converted to integers, using the rounding mode
+	COP_LENIENT_NUMERIC_STRING = 8
+	// Whether to collect all errors of slice, map and struct elements, instead of stopping at the first one
+	COP_COLLECT_ERRORS = 16
+	// Whether zero source values (0, "", 0.0) converted to pointer destinations result in nil
+	COP_ZERO_TO_NIL = 32
 )
 
 // ConvertOp returns the function to convert a primitive value of type src
@@ -35,6 +42,19 @@ type Config struct {
 	Flags         uint
 	FloatFormat   string
 	ComplexFormat string
+	Rounding      RoundingMode
+	Precision     PrecisionMode
+	Overflow      OverflowMode
+	NaN           NaNPolicy
+	// How the value stored in interface destinations is chosen
+	Interface InterfaceMode
+	// Fixed-point scale of integers converted from and to strings, 0 to disable.
+	// With scale 2, "12.34" is converted to 1234.
+	Scale int
+	// Wraps the conversion functions returned by ConvertOpType
+	Middlewares []ConvertMiddleware
+	// Called for non-fatal conversion problems, like precision loss in PRECISION_WARN mode
+	Warning func(err error)
 }
 
 func NewConfig() *Config {
@@ -54,11 +74,54 @@ func (c *Config) AddFlags(flags uint) *Config {
 	return c
 }
 
+func (c *Config) SetRounding(rounding RoundingMode) *Config {
+	c.Rounding = rounding
+	return c
+}
+
+func (c *Config) SetPrecision(precision PrecisionMode) *Config {
+	c.Precision = precision
+	return c
+}
+
+func (c *Config) SetOverflow(overflow OverflowMode) *Config {
+	c.Overflow = overflow
+	return c
+}
+
+func (c *Config) SetNaN(nan NaNPolicy) *Config {
+	c.NaN = nan
+	return c
+}
+
+func (c *Config) SetInterface(mode InterfaceMode) *Config {
+	c.Interface = mode
+	return c
+}
+
+func (c *Config) SetScale(scale int) *Config {
+	c.Scale = scale
+	return c
+}
+
+func (c *Config) SetWarning(warning func(err error)) *Config {
+	c.Warning = warning
+	return c
+}
+
 func (c Config) Dup() *Config {
 	return &Config{
 		Flags:         c.Flags,
 		FloatFormat:   c.FloatFormat,
 		ComplexFormat: c.ComplexFormat,
+		Rounding:      c.Rounding,
+		Precision:     c.Precision,
+		Overflow:      c.Overflow,
+		NaN:           c.NaN,
+		Interface:     c.Interface,
+		Scale:         c.Scale,
+		Warning:       c.Warning,
+		Middlewares:   append([]ConvertMiddleware(nil), c.Middlewares...),
 	}
 }
 
@@ -66,49 +129,168 @@ func (c Config) Dup() *Config {
 type ConvertOpFunc func(reflect.Value, reflect.Type) (reflect.Value, error)
 
 func (c Config) ConvertOp(src, dst reflect.Value) ConvertOpFunc {
+	if !dst.IsValid() {
+		return nil
+	}
 	return c.ConvertOpType(src, dst.Type())
 }
 
 func (c Config) ConvertOpType(src reflect.Value, dstType reflect.Type) ConvertOpFunc {
-	uk_src := UnderliningValueKind(src)
+	cop := c.convertOpValue(src, dstType)
+	if cop == nil {
+		return nil
+	}
+	return cvtRecover(c.applyMiddlewares(cop))
+}
+
+// Returns the conversion function without middlewares, for nested conversions like container elements,
+// which are part of a conversion that already applied them.
+func (c Config) convertOpValue(src reflect.Value, dstType reflect.Type) ConvertOpFunc {
+	if !src.IsValid() {
+		return nil
+	}
+	cop, _, _ := c.convertOp(valueSource(src), dstType)
+	return cop
+}
+
+// Source information used to select the conversion function, which can come from a value or only from a type.
+type convertSource struct {
+	// type of the source
+	typ reflect.Type
+	// underlining type, after all pointer and interface dereferences
+	utype reflect.Type
+	// whether the source is nil
+	isNil bool
+	// whether the source is nil in any amount of pointer indirection
+	anyNil bool
+	// whether the source is only a type, so interface contents are not known
+	static bool
+}
+
+func valueSource(v reflect.Value) convertSource {
+	return convertSource{
+		typ:    v.Type(),
+		utype:  UnderliningValueType(v),
+		isNil:  (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil(),
+		anyNil: UnderliningValueIsNil(v),
+	}
+}
+
+func typeSource(t reflect.Type) convertSource {
+	return convertSource{
+		typ:    t,
+		utype:  UnderliningType(t),
+		static: true,
+	}
+}
+
+// Returns a copy of the source as a nil value.
+func (s convertSource) asNil() convertSource {
+	s.isNil = true
+	s.anyNil = true
+	return s
+}
+
+// Selects the conversion function, also returning the strategy used and the flags that were consulted.
+func (c Config) convertOp(src convertSource, dstType reflect.Type) (ConvertOpFunc, ConvertStrategy, uint) {
+	uk_src := src.utype.Kind()
 	uk_dst := UnderliningTypeKind(dstType)
 
-	may_be_direct_assignable := (dstType == nil || UnderliningType(src.Type()).AssignableTo(UnderliningType(dstType))) ||
-		src.Kind() == reflect.Interface || dstType.Kind() == reflect.Interface
+	may_be_direct_assignable := (dstType == nil || UnderliningType(src.typ).AssignableTo(UnderliningType(dstType))) ||
+		src.typ.Kind() == reflect.Interface || dstType.Kind() == reflect.Interface
+
+	var flags uint
+
+	zero_to_nil := false
+	if dstType != nil && dstType.Kind() == reflect.Ptr {
+		flags |= COP_ZERO_TO_NIL
+		zero_to_nil = (c.Flags & COP_ZERO_TO_NIL) == COP_ZERO_TO_NIL
+	}
 
 	// these funcions are used to only allow setting nil after all the type compatibility checks are done
-	proc_ret := func(f ConvertOpFunc) ConvertOpFunc {
-		return f
+	proc_ret := func(f ConvertOpFunc, s ConvertStrategy) (ConvertOpFunc, ConvertStrategy, uint) {
+		if zero_to_nil {
+			f = cvtZeroToNil(f)
+		}
+		// interface destinations store the source as is
+		if s != STRATEGY_INTERFACE && (src.typ.Kind() == reflect.Ptr || src.typ.Kind() == reflect.Interface) {
+			f = cvtNilPointerChain(f)
+		}
+		return f, s, flags
 	}
 
-	proc_ret_nil := func(f ConvertOpFunc) ConvertOpFunc {
-		return cvtNil
+	proc_ret_nil := func(f ConvertOpFunc, s ConvertStrategy) (ConvertOpFunc, ConvertStrategy, uint) {
+		return cvtNil, STRATEGY_NIL, flags
+	}
+
+	// database/sql nullable wrapper destination, where nil is a valid value
+	if IsSQLNullType(UnderliningType(dstType)) {
+		return c.sqlNullWrapOp(src, dstType)
 	}
 
 	// if src is nil, check if dst is nullable
-	if (src.Kind() == reflect.Ptr || src.Kind() == reflect.Interface) && src.IsNil() {
+	if src.isNil {
 		if dstType == nil || dstType.Kind() == reflect.Ptr || dstType.Kind() == reflect.Interface {
 			//return cvtNil
 			proc_ret = proc_ret_nil
 		} else if !((c.Flags & COP_ALLOW_NIL_TO_ZERO_VALUE) == COP_ALLOW_NIL_TO_ZERO_VALUE) {
-			return cvtError(errors.New("Copying nil to zero value not allowed"))
+			return cvtError(errors.New("Copying nil to zero value not allowed")), STRATEGY_ERROR, COP_ALLOW_NIL_TO_ZERO_VALUE
 		} else {
 			//return cvtNil
+			flags |= COP_ALLOW_NIL_TO_ZERO_VALUE
 			proc_ret = proc_ret_nil
 		}
 	}
 
 	// target type is interface
 	if uk_dst == reflect.Interface {
-		return proc_ret(cvtAnyInterface)
+		if cop := c.interfaceOp(src, dstType); cop != nil {
+			return proc_ret(cop, STRATEGY_INTERFACE)
+		}
+		return nil, STRATEGY_INVALID, flags
+	}
+
+	// the contents of the interface are only known at runtime
+	if src.static && uk_src == reflect.Interface {
+		return nil, STRATEGY_DYNAMIC, flags
+	}
+
+	// database/sql nullable wrapper source
+	if !src.anyNil && IsSQLNullType(src.utype) {
+		return c.sqlNullUnwrapOp(src, dstType)
+	}
+
+	// math/big numbers
+	if IsBigType(src.utype) || IsBigType(UnderliningType(dstType)) {
+		if cop := c.bigOp(src.utype, UnderliningType(dstType)); cop != nil {
+			return proc_ret(cop, STRATEGY_BIG)
+		}
+		return nil, STRATEGY_INVALID, flags
+	}
+
+	// single-field wrapper structs, other structs are converted field by field
+	src_wrapper, dst_wrapper := WrapperField(src.utype) >= 0, WrapperField(UnderliningType(dstType)) >= 0
+	if (src_wrapper || dst_wrapper) && src.utype != UnderliningType(dstType) &&
+		(uk_src != reflect.Struct || uk_dst != reflect.Struct || (src_wrapper && dst_wrapper)) {
+		if cop := c.wrapperOp(src.utype, UnderliningType(dstType)); cop != nil {
+			return proc_ret(cop, STRATEGY_WRAPPER)
+		}
+	}
+
+	// slices, arrays, maps and structs
+	if kindIsContainer(uk_src) && kindIsContainer(uk_dst) {
+		if cop := c.containerOp(src.utype, UnderliningType(dstType)); cop != nil {
+			return proc_ret(cop, STRATEGY_CONTAINER)
+		}
+		return nil, STRATEGY_INVALID, flags
 	}
 
 	// dst and src have same underlying type.
 	if may_be_direct_assignable && uk_src == uk_dst && KindIsSimpleValue(uk_src) && KindIsSimpleValue(uk_dst) {
-		if dstType == nil || src.Kind() == reflect.Ptr || dstType.Kind() == reflect.Ptr || src.Kind() == reflect.Interface || dstType.Kind() == reflect.Interface {
-			return proc_ret(cvtDirectPointer)
+		if dstType == nil || src.typ.Kind() == reflect.Ptr || dstType.Kind() == reflect.Ptr || src.typ.Kind() == reflect.Interface || dstType.Kind() == reflect.Interface {
+			return proc_ret(cvtDirectPointer, STRATEGY_DIRECT_POINTER)
 		} else {
-			return proc_ret(cvtDirect)
+			return proc_ret(cvtDirect, STRATEGY_DIRECT)
 		}
 	}
 
@@ -116,74 +298,96 @@ func (c Config) ConvertOpType(src reflect.Value, dstType reflect.Type) ConvertOp
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		switch uk_dst {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-			return proc_ret(cvtInt)
+			return proc_ret(cvtInt(c.Overflow), STRATEGY_NUMERIC)
 		case reflect.Float32, reflect.Float64:
-			return proc_ret(cvtIntFloat)
+			return proc_ret(cvtIntFloat(c.precisionCheck()), STRATEGY_NUMERIC)
 		case reflect.String:
-			return proc_ret(cvtIntString)
+			if c.Scale > 0 {
+				return proc_ret(cvtIntStringScaled(c.Scale), STRATEGY_FORMAT)
+			}
+			return proc_ret(cvtIntString, STRATEGY_FORMAT)
 		}
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 		switch uk_dst {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-			return proc_ret(cvtUint)
+			return proc_ret(cvtUint(c.Overflow), STRATEGY_NUMERIC)
 		case reflect.Float32, reflect.Float64:
-			return proc_ret(cvtUintFloat)
+			return proc_ret(cvtUintFloat(c.precisionCheck()), STRATEGY_NUMERIC)
 		case reflect.String:
-			return proc_ret(cvtUintString)
+			if c.Scale > 0 {
+				return proc_ret(cvtUintStringScaled(c.Scale), STRATEGY_FORMAT)
+			}
+			return proc_ret(cvtUintString, STRATEGY_FORMAT)
 		}
 
 	case reflect.Float32, reflect.Float64:
 		switch uk_dst {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			return proc_ret(cvtFloatInt)
+			return proc_ret(cvtFloatInt(c.Rounding, c.Overflow, c.NaN), STRATEGY_NUMERIC)
 		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-			return proc_ret(cvtFloatUint)
+			return proc_ret(cvtFloatUint(c.Rounding, c.Overflow, c.NaN), STRATEGY_NUMERIC)
 		case reflect.Float32, reflect.Float64:
-			return proc_ret(cvtFloat)
+			return proc_ret(cvtFloat(c.precisionCheck()), STRATEGY_NUMERIC)
 		case reflect.String:
-			return proc_ret(cvtFloatString(c.FloatFormat))
+			return proc_ret(cvtFloatString(c.FloatFormat), STRATEGY_FORMAT)
 		}
 
 	case reflect.Complex64, reflect.Complex128:
 		switch uk_dst {
 		case reflect.Complex64, reflect.Complex128:
-			return proc_ret(cvtComplex)
+			return proc_ret(cvtComplex, STRATEGY_NUMERIC)
 		case reflect.String:
-			return proc_ret(cvtComplexString(c.ComplexFormat))
+			return proc_ret(cvtComplexString(c.ComplexFormat), STRATEGY_FORMAT)
 		}
 
 	case reflect.String:
 		switch uk_dst {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			return proc_ret(cvtStringInt)
+			if c.Scale > 0 {
+				return proc_ret(cvtStringIntScaled(c.Scale, c.Overflow), STRATEGY_PARSE)
+			}
+			flags |= COP_LENIENT_NUMERIC_STRING
+			if (c.Flags & COP_LENIENT_NUMERIC_STRING) == COP_LENIENT_NUMERIC_STRING {
+				return proc_ret(cvtStringIntLenient(c.Rounding, c.Overflow, c.NaN), STRATEGY_PARSE)
+			}
+			return proc_ret(cvtStringInt(c.Overflow), STRATEGY_PARSE)
 		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-			return proc_ret(cvtStringUint)
+			if c.Scale > 0 {
+				return proc_ret(cvtStringIntScaled(c.Scale, c.Overflow), STRATEGY_PARSE)
+			}
+			flags |= COP_LENIENT_NUMERIC_STRING
+			if (c.Flags & COP_LENIENT_NUMERIC_STRING) == COP_LENIENT_NUMERIC_STRING {
+				return proc_ret(cvtStringUintLenient(c.Rounding, c.Overflow, c.NaN), STRATEGY_PARSE)
+			}
+			return proc_ret(cvtStringUint(c.Overflow), STRATEGY_PARSE)
 		case reflect.Float32, reflect.Float64:
-			return proc_ret(cvtStringFloat(c.FloatFormat))
+			return proc_ret(cvtStringFloat(c.FloatFormat, c.precisionCheck()), STRATEGY_PARSE)
 		case reflect.Complex64, reflect.Complex128:
-			return proc_ret(cvtStringComplex(c.ComplexFormat))
+			return proc_ret(cvtStringComplex(c.ComplexFormat), STRATEGY_PARSE)
 		case reflect.String:
-			return proc_ret(cvtDirectPointer)
+			return proc_ret(cvtDirectPointer, STRATEGY_DIRECT_POINTER)
 		case reflect.Slice:
+			flags |= COP_ALLOW_STRING_TO_SLICE
 			if (c.Flags & COP_ALLOW_STRING_TO_SLICE) == COP_ALLOW_STRING_TO_SLICE {
-				switch dstType.Elem().Kind() {
+				switch UnderliningType(dstType).Elem().Kind() {
 				case reflect.Uint8:
-					return proc_ret(cvtStringBytes)
+					return proc_ret(cvtStringBytes, STRATEGY_SLICE)
 				case reflect.Int32:
-					return proc_ret(cvtStringRunes)
+					return proc_ret(cvtStringRunes, STRATEGY_SLICE)
 				}
 			}
 		}
 
 	case reflect.Slice:
 		if uk_dst == reflect.String {
+			flags |= COP_ALLOW_SLICE_TO_SRING
 			if (c.Flags & COP_ALLOW_SLICE_TO_SRING) == COP_ALLOW_SLICE_TO_SRING {
-				switch src.Elem().Kind() {
+				switch src.utype.Elem().Kind() {
 				case reflect.Uint8:
-					return proc_ret(cvtBytesString)
+					return proc_ret(cvtBytesString, STRATEGY_SLICE)
 				case reflect.Int32:
-					return proc_ret(cvtRunesString)
+					return proc_ret(cvtRunesString, STRATEGY_SLICE)
 				}
 			}
 		}
@@ -198,12 +402,12 @@ func (c Config) ConvertOpType(src reflect.Value, dstType reflect.Type) ConvertOp
 		}
 	*/
 
-	return nil
+	return nil, STRATEGY_INVALID, flags
 }
 
 // makeInt returns a Value of type t equal to bits (possibly truncated),
 // where t is a signed or unsigned int type.
-func makeInt(bits uint64, t reflect.Type) reflect.Value {
+func makeInt(bits uint64, t reflect.Type) (reflect.Value, error) {
 	root, last := NewUnderliningValue(t)
 	var newvalue reflect.Value
 	switch last.Kind() {
@@ -217,6 +421,8 @@ func makeInt(bits uint64, t reflect.Type) reflect.Value {
 		newvalue = reflect.ValueOf(uint32(bits))
 	case reflect.Uint64:
 		newvalue = reflect.ValueOf(uint64(bits))
+	case reflect.Uintptr:
+		newvalue = reflect.ValueOf(uintptr(bits))
 	case reflect.Int:
 		newvalue = reflect.ValueOf(int(bits))
 	case reflect.Int8:
@@ -228,21 +434,14 @@ func makeInt(bits uint64, t reflect.Type) reflect.Value {
 	case reflect.Int64:
 		newvalue = reflect.ValueOf(int64(bits))
 	default:
-		panic(fmt.Sprintf("Invalid value for makeInt: %s", last.Kind().String()))
+		return reflect.Value{}, fmt.Errorf("Invalid value for makeInt: %s", last.Kind().String())
 	}
-
-	if !newvalue.Type().AssignableTo(last.Type()) {
-		// named values are not directly assignable
-		newvalue = newvalue.Convert(last.Type())
-	}
-
-	last.Set(newvalue)
-	return root
+	return root, setUnderlining(last, newvalue)
 }
 
 // makeFloat returns a Value of type t equal to v (possibly truncated to float32),
 // where t is a float32 or float64 type.
-func makeFloat(v float64, t reflect.Type) reflect.Value {
+func makeFloat(v float64, t reflect.Type) (reflect.Value, error) {
 	root, last := NewUnderliningValue(t)
 	var newvalue reflect.Value
 	switch last.Kind() {
@@ -251,21 +450,14 @@ func makeFloat(v float64, t reflect.Type) reflect.Value {
 	case reflect.Float64:
 		newvalue = reflect.ValueOf(float64(v))
 	default:
-		panic("Invalid value for makeFloat")
+		return reflect.Value{}, fmt.Errorf("Invalid value for makeFloat: %s", last.Kind().String())
 	}
-
-	if !newvalue.Type().AssignableTo(last.Type()) {
-		// named values are not directly assignable
-		newvalue = newvalue.Convert(last.Type())
-	}
-	last.Set(newvalue)
-
-	return root
+	return root, setUnderlining(last, newvalue)
 }
 
 // makeComplex returns a Value of type t equal to v (possibly truncated to complex64),
 // where t is a complex64 or complex128 type.
-func makeComplex(v complex128, t reflect.Type) reflect.Value {
+func makeComplex(v complex128, t reflect.Type) (reflect.Value, error) {
 	root, last := NewUnderliningValue(t)
 	var newvalue reflect.Value
 	switch last.Kind() {
@@ -274,49 +466,50 @@ func makeComplex(v complex128, t reflect.Type) reflect.Value {
 	case reflect.Complex128:
 		newvalue = reflect.ValueOf(complex128(v))
 	default:
-		panic("Invalid value for makeComplex")
+		return reflect.Value{}, fmt.Errorf("Invalid value for makeComplex: %s", last.Kind().String())
 	}
-
-	if !newvalue.Type().AssignableTo(last.Type()) {
-		// named values are not directly assignable
-		newvalue = newvalue.Convert(last.Type())
-	}
-	last.Set(newvalue)
-
-	return root
+	return root, setUnderlining(last, newvalue)
 }
 
-func makeString(v string, t reflect.Type) reflect.Value {
+func makeString(v string, t reflect.Type) (reflect.Value, error) {
 	root, last := NewUnderliningValue(t)
-	newvalue := reflect.ValueOf(v)
-	if !newvalue.Type().AssignableTo(last.Type()) {
-		// named values are not directly assignable
-		newvalue = newvalue.Convert(last.Type())
-	}
-	last.Set(newvalue)
-	return root
+	return root, setUnderlining(last, reflect.ValueOf(v))
 }
 
-func makeBytes(v []byte, t reflect.Type) reflect.Value {
+func makeBytes(v []byte, t reflect.Type) (reflect.Value, error) {
 	root, last := NewUnderliningValue(t)
-	newvalue := reflect.ValueOf(v)
-	if !newvalue.Type().AssignableTo(last.Type()) {
-		// named values are not directly assignable
-		newvalue = newvalue.Convert(last.Type())
-	}
-	last.Set(newvalue)
-	return root
+	return root, setUnderlining(last, reflect.ValueOf(v))
 }
 
-func makeRunes(v []rune, t reflect.Type) reflect.Value {
+func makeRunes(v []rune, t reflect.Type) (reflect.Value, error) {
 	root, last := NewUnderliningValue(t)
-	newvalue := reflect.ValueOf(string(v))
-	if !newvalue.Type().AssignableTo(last.Type()) {
+	return root, setUnderlining(last, reflect.ValueOf(v))
+}
+
+// Sets the value, converting named values that are not directly assignable.
+// Slices with named element types are converted element by element.
+func setUnderlining(last, newvalue reflect.Value) error {
+	lt := last.Type()
+	switch {
+	case newvalue.Type().AssignableTo(lt):
+	case newvalue.Kind() == reflect.Slice && lt.Kind() == reflect.Slice && newvalue.Type().Elem().ConvertibleTo(lt.Elem()):
+		if newvalue.IsNil() {
+			return nil
+		}
+		n := newvalue.Len()
+		sv := reflect.MakeSlice(lt, n, n)
+		for i := 0; i < n; i++ {
+			sv.Index(i).Set(newvalue.Index(i).Convert(lt.Elem()))
+		}
+		newvalue = sv
+	case newvalue.Type().ConvertibleTo(lt):
 		// named values are not directly assignable
-		newvalue = newvalue.Convert(last.Type())
+		newvalue = newvalue.Convert(lt)
+	default:
+		return fmt.Errorf("Cannot set value of type %s to %s", newvalue.Type().String(), lt.String())
 	}
 	last.Set(newvalue)
-	return root
+	return nil
 }
 
 func cvtError(err error) ConvertOpFunc {
@@ -331,113 +524,263 @@ func cvtError(err error) ConvertOpFunc {
 // to type t, where t is any signed or unsigned int type.
 
 // ConvertOp: intXX -> [u]intXX
-func cvtInt(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-	return makeInt(uint64(UnderliningValue(v).Int()), t), nil
+func cvtInt(overflow OverflowMode) ConvertOpFunc {
+	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+		bits, err := intRangeBits(UnderliningValue(v).Int(), UnderliningType(t), overflow)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return makeInt(bits, t)
+	}
 }
 
 // ConvertOp: uintXX -> [u]intXX
-func cvtUint(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-	return makeInt(UnderliningValue(v).Uint(), t), nil
+func cvtUint(overflow OverflowMode) ConvertOpFunc {
+	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+		bits, err := uintRangeBits(UnderliningValue(v).Uint(), UnderliningType(t), overflow)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return makeInt(bits, t)
+	}
 }
 
 // ConvertOp: floatXX -> intXX
-func cvtFloatInt(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-	return makeInt(uint64(int64(UnderliningValue(v).Float())), t), nil
+func cvtFloatInt(rounding RoundingMode, overflow OverflowMode, nan NaNPolicy) ConvertOpFunc {
+	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+		cv, err := roundFloat(UnderliningValue(v).Float(), rounding)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("Error converting float to int: %v", err)
+		}
+		bits, err := floatRangeBits(cv, UnderliningType(t), overflow, nan)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return makeInt(bits, t)
+	}
 }
 
 // ConvertOp: floatXX -> uintXX
-func cvtFloatUint(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-	return makeInt(uint64(UnderliningValue(v).Float()), t), nil
+func cvtFloatUint(rounding RoundingMode, overflow OverflowMode, nan NaNPolicy) ConvertOpFunc {
+	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+		cv, err := roundFloat(UnderliningValue(v).Float(), rounding)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("Error converting float to uint: %v", err)
+		}
+		bits, err := floatRangeBits(cv, UnderliningType(t), overflow, nan)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return makeInt(bits, t)
+	}
 }
 
 // ConvertOp: intXX -> floatXX
-func cvtIntFloat(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-	return makeFloat(float64(UnderliningValue(v).Int()), t), nil
+func cvtIntFloat(check func(error) error) ConvertOpFunc {
+	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+		i := UnderliningValue(v).Int()
+		if check != nil {
+			if perr := checkIntFloatPrecision(i, UnderliningTypeKind(t)); perr != nil {
+				if err := check(perr); err != nil {
+					return reflect.Value{}, err
+				}
+			}
+		}
+		return makeFloat(float64(i), t)
+	}
 }
 
 // ConvertOp: uintXX -> floatXX
-func cvtUintFloat(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-	return makeFloat(float64(UnderliningValue(v).Uint()), t), nil
+func cvtUintFloat(check func(error) error) ConvertOpFunc {
+	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+		u := UnderliningValue(v).Uint()
+		if check != nil {
+			if perr := checkUintFloatPrecision(u, UnderliningTypeKind(t)); perr != nil {
+				if err := check(perr); err != nil {
+					return reflect.Value{}, err
+				}
+			}
+		}
+		return makeFloat(float64(u), t)
+	}
 }
 
 // ConvertOp: floatXX -> floatXX
-func cvtFloat(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-	return makeFloat(UnderliningValue(v).Float(), t), nil
+func cvtFloat(check func(error) error) ConvertOpFunc {
+	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+		f := UnderliningValue(v).Float()
+		if check != nil {
+			if _, perr := checkFloatPrecision(f, UnderliningTypeKind(t)); perr != nil {
+				if err := check(perr); err != nil {
+					return reflect.Value{}, err
+				}
+			}
+		}
+		return makeFloat(f, t)
+	}
 }
 
 // ConvertOp: complexXX -> complexXX
 func cvtComplex(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-	return makeComplex(UnderliningValue(v).Complex(), t), nil
+	return makeComplex(UnderliningValue(v).Complex(), t)
 }
 
 // ConvertOp: intXX -> string
 func cvtIntString(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-	return makeString(strconv.FormatInt(UnderliningValue(v).Int(), 10), t), nil
+	return makeString(strconv.FormatInt(UnderliningValue(v).Int(), 10), t)
 }
 
 // ConvertOp: uintXX -> string
 func cvtUintString(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-	return makeString(strconv.FormatUint(UnderliningValue(v).Uint(), 10), t), nil
+	return makeString(strconv.FormatUint(UnderliningValue(v).Uint(), 10), t)
 }
 
 // ConvertOp: floatXX -> string
 func cvtFloatString(format string) ConvertOpFunc {
 	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-		return makeString(fmt.Sprintf(format, UnderliningValue(v).Float()), t), nil
+		return makeString(fmt.Sprintf(format, UnderliningValue(v).Float()), t)
 	}
 }
 
 /*
 func cvtFloatString(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-	return makeString(fmt.Sprintf("%f", IndirectPtrInterface(v).Float()), t), nil
+	return makeString(fmt.Sprintf("%f", IndirectPtrInterface(v).Float()), t)
 }
 */
 
 // ConvertOp: complexXX -> string
 func cvtComplexString(format string) ConvertOpFunc {
 	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-		return makeString(fmt.Sprintf(format, UnderliningValue(v).Complex()), t), nil
+		return makeString(fmt.Sprintf(format, UnderliningValue(v).Complex()), t)
 	}
 }
 
 /*
 func cvtComplexString(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-	return makeString(fmt.Sprintf("%g", IndirectPtrInterface(v).Complex()), t), nil
+	return makeString(fmt.Sprintf("%g", IndirectPtrInterface(v).Complex()), t)
 }
 */
 
 // ConvertOp: []byte -> string
 func cvtBytesString(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-	return makeString(string(UnderliningValue(v).Bytes()), t), nil
+	return makeString(string(UnderliningValue(v).Bytes()), t)
 }
 
 // ConvertOp: string -> intXX
-func cvtStringInt(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-	cv, err := strconv.ParseInt(UnderliningValue(v).String(), 10, 64)
-	if err != nil {
-		return reflect.Value{}, fmt.Errorf("Error converting string to int: %v", err)
+func cvtStringInt(overflow OverflowMode) ConvertOpFunc {
+	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+		cv, err := parseIntRange(UnderliningValue(v).String(), overflow)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("Error converting string to int: %w", err)
+		}
+		bits, err := intRangeBits(cv, UnderliningType(t), overflow)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return makeInt(bits, t)
 	}
-	return makeInt(uint64(cv), t), nil
 }
 
 // ConvertOp: string -> uintXX
-func cvtStringUint(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-	cv, err := strconv.ParseUint(UnderliningValue(v).String(), 10, 64)
-	if err != nil {
-		return reflect.Value{}, fmt.Errorf("Error converting string to uint: %v", err)
+func cvtStringUint(overflow OverflowMode) ConvertOpFunc {
+	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+		cv, err := parseUintRange(UnderliningValue(v).String(), overflow)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("Error converting string to uint: %w", err)
+		}
+		bits, err := uintRangeBits(cv, UnderliningType(t), overflow)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return makeInt(bits, t)
+	}
+}
+
+// Parses the int string, returning the clamped value if out of range and the overflow mode is saturate.
+func parseIntRange(str string, overflow OverflowMode) (int64, error) {
+	cv, err := strconv.ParseInt(str, 10, 64)
+	if err != nil && errors.Is(err, strconv.ErrRange) {
+		if overflow == OVERFLOW_SATURATE {
+			// ParseInt returns the clamped value on range errors
+			return cv, nil
+		} else if overflow == OVERFLOW_ERROR {
+			return 0, fmt.Errorf("%v: %w", err, ErrOverflow)
+		}
+	}
+	return cv, err
+}
+
+// Parses the uint string, returning the clamped value if out of range and the overflow mode is saturate.
+// Negative values are clamped to 0.
+func parseUintRange(str string, overflow OverflowMode) (uint64, error) {
+	cv, err := strconv.ParseUint(str, 10, 64)
+	if err == nil || overflow == OVERFLOW_WRAP {
+		return cv, err
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		if overflow == OVERFLOW_SATURATE {
+			// ParseUint returns the clamped value on range errors
+			return cv, nil
+		}
+		return 0, fmt.Errorf("%v: %w", err, ErrOverflow)
+	}
+	// negative values are out of the unsigned range
+	if _, ierr := strconv.ParseInt(str, 10, 64); strings.HasPrefix(str, "-") && (ierr == nil || errors.Is(ierr, strconv.ErrRange)) {
+		if overflow == OVERFLOW_SATURATE {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%v: %w", err, ErrOverflow)
+	}
+	return cv, err
+}
+
+// ConvertOp: string -> intXX, accepting fractional values
+func cvtStringIntLenient(rounding RoundingMode, overflow OverflowMode, nan NaNPolicy) ConvertOpFunc {
+	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+		str := UnderliningValue(v).String()
+		if _, err := strconv.ParseInt(str, 10, 64); err == nil || errors.Is(err, strconv.ErrRange) {
+			return cvtStringInt(overflow)(v, t)
+		}
+		fv, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("Error converting string to int: %v", err)
+		}
+		return cvtFloatInt(rounding, overflow, nan)(reflect.ValueOf(fv), t)
+	}
+}
+
+// ConvertOp: string -> uintXX, accepting fractional values
+func cvtStringUintLenient(rounding RoundingMode, overflow OverflowMode, nan NaNPolicy) ConvertOpFunc {
+	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+		str := UnderliningValue(v).String()
+		if _, err := strconv.ParseUint(str, 10, 64); err == nil || errors.Is(err, strconv.ErrRange) {
+			return cvtStringUint(overflow)(v, t)
+		}
+		fv, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("Error converting string to uint: %v", err)
+		}
+		return cvtFloatUint(rounding, overflow, nan)(reflect.ValueOf(fv), t)
 	}
-	return makeInt(uint64(cv), t), nil
 }
 
 // ConvertOp: string -> floatXX
-func cvtStringFloat(format string) ConvertOpFunc {
+func cvtStringFloat(format string, check func(error) error) ConvertOpFunc {
 	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
 		var cv float64
 		_, err := fmt.Sscanf(UnderliningValue(v).String(), format, &cv)
 		if err != nil {
 			return reflect.Value{}, fmt.Errorf("Error converting string to float: %v", err)
 		}
-		return makeFloat(float64(cv), t), nil
+		if check != nil {
+			// parsed decimal values are rarely exact, so only overflow is checked
+			if _, perr := checkFloatPrecision(cv, UnderliningTypeKind(t)); perr != nil && errors.Is(perr, ErrOverflow) {
+				if err := check(perr); err != nil {
+					return reflect.Value{}, err
+				}
+			}
+		}
+		return makeFloat(float64(cv), t)
 	}
 }
 
@@ -448,7 +791,7 @@ func cvtStringFloat(v reflect.Value, t reflect.Type) (reflect.Value, error) {
 	if err != nil {
 		return reflect.Value{}, fmt.Errorf("Error converting string to float: %v", err)
 	}
-	return makeFloat(float64(cv), t), nil
+	return makeFloat(float64(cv), t)
 }
 */
 
@@ -461,7 +804,7 @@ func cvtStringComplex(format string) ConvertOpFunc {
 		if err != nil {
 			return reflect.Value{}, fmt.Errorf("Error converting string to complex: %v", err)
 		}
-		return makeComplex(complex128(cv), t), nil
+		return makeComplex(complex128(cv), t)
 	}
 }
 
@@ -472,23 +815,29 @@ func cvtStringComplex(v reflect.Value, t reflect.Type) (reflect.Value, error) {
 	if err != nil {
 		return reflect.Value{}, fmt.Errorf("Error converting string to complex: %v", err)
 	}
-	return makeComplex(complex128(cv), t), nil
+	return makeComplex(complex128(cv), t)
 }
 */
 
 // ConvertOp: string -> []byte
 func cvtStringBytes(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-	return makeBytes([]byte(UnderliningValue(v).String()), t), nil
+	return makeBytes([]byte(UnderliningValue(v).String()), t)
 }
 
 // ConvertOp: []rune -> string
 func cvtRunesString(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-	return makeString(string(UnderliningValue(v).Interface().([]int32)), t), nil
+	// the element type may be a named int32 type
+	sv := UnderliningValue(v)
+	runes := make([]rune, sv.Len())
+	for i := range runes {
+		runes[i] = rune(sv.Index(i).Int())
+	}
+	return makeString(string(runes), t)
 }
 
 // ConvertOp: string -> []rune
 func cvtStringRunes(v reflect.Value, t reflect.Type) (reflect.Value, error) {
-	return makeRunes([]rune(UnderliningValue(v).String()), t), nil
+	return makeRunes([]rune(UnderliningValue(v).String()), t)
 }
 
 // ConvertOp: direct copy
@@ -501,10 +850,51 @@ func cvtDirect(v reflect.Value, typ reflect.Type) (reflect.Value, error) {
 // ConvertOp: direct copy with pointers involved
 func cvtDirectPointer(v reflect.Value, typ reflect.Type) (reflect.Value, error) {
 	root, last := NewUnderliningValue(typ)
-	last.Set(UnderliningValue(v))
+	if err := setUnderlining(last, UnderliningValue(v)); err != nil {
+		return reflect.Value{}, err
+	}
 	return root, nil
 }
 
+// ConvertOp: nil when the source value is zero, else calls the conversion function
+func cvtZeroToNil(cop ConvertOpFunc) ConvertOpFunc {
+	return func(v reflect.Value, typ reflect.Type) (reflect.Value, error) {
+		if uv := UnderliningValue(v); KindIsSimpleValue(uv.Kind()) && uv.IsZero() {
+			return reflect.Zero(typ), nil
+		}
+		return cop(v, typ)
+	}
+}
+
+// ConvertOp: error when a pointer or interface inside the source pointer chain is nil, else calls the
+// conversion function
+func cvtNilPointerChain(cop ConvertOpFunc) ConvertOpFunc {
+	return func(v reflect.Value, typ reflect.Type) (reflect.Value, error) {
+		if !v.IsNil() && UnderliningValueIsNil(v) {
+			return reflect.Value{}, ErrNilPointerChain
+		}
+		return cop(v, typ)
+	}
+}
+
+// The conversion function panicked, the panic value is in the error message.
+var ErrPanic = errors.New("Conversion panicked")
+
+// ConvertOp: returns an error instead of panicking
+func cvtRecover(cop ConvertOpFunc) ConvertOpFunc {
+	return func(v reflect.Value, typ reflect.Type) (ret reflect.Value, err error) {
+		if !v.IsValid() {
+			return reflect.Value{}, fmt.Errorf("Invalid conversion from invalid value to %s", typeString(typ))
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				ret, err = reflect.Value{}, fmt.Errorf("Error converting %s to %s: %w: %v", v.Type().String(), typeString(typ), ErrPanic, r)
+			}
+		}()
+		return cop(v, typ)
+	}
+}
+
 // converOp: nil when source value is nil
 func cvtNil(v reflect.Value, typ reflect.Type) (reflect.Value, error) {
 	return reflect.Zero(typ), nil
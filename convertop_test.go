@@ -237,3 +237,51 @@ func TestNamedType(t *testing.T) {
 		t.Fatalf("Enum value should be TI2_SECOND, is %v", v2)
 	}
 }
+
+func TestZeroToNil(t *testing.T) {
+	c := NewConfig().AddFlags(COP_ZERO_TO_NIL)
+
+	var pi *int
+	var ps **string
+
+	tests := []struct {
+		value   interface{}
+		dstType reflect.Type
+		isNil   bool
+	}{
+		{0, reflect.TypeOf(pi), true},
+		{"", reflect.TypeOf(ps), true},
+		{0.0, reflect.TypeOf(pi), true},
+		{"0", reflect.TypeOf(pi), false},
+		{5, reflect.TypeOf(pi), false},
+		{"x", reflect.TypeOf(ps), false},
+	}
+
+	for _, test := range tests {
+		cv, err := c.Convert(reflect.ValueOf(test.value), test.dstType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cv.IsNil() != test.isNil {
+			t.Fatalf("Converting %#v to %s should be nil: %v", test.value, test.dstType.String(), test.isNil)
+		}
+	}
+
+	// non-pointer destinations are not affected
+	cv, err := c.Convert(reflect.ValueOf(""), reflect.TypeOf(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.String() != "" {
+		t.Fatal("Value should be an empty string")
+	}
+
+	// disabled by default
+	cv, err = Convert(reflect.ValueOf(0), reflect.TypeOf(pi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.IsNil() {
+		t.Fatal("Value should not be nil")
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Package csvconv reads and writes CSV records into structs, converting each cell with rprim.
+
+Struct fields are mapped to CSV columns using the "csv" tag, or the field name if no tag is set.
+Fields tagged with "-" are ignored.
+
+	type Item struct {
+		ID    int      `csv:"id"`
+		Price *float64 `csv:"price"`
+		Notes string   `csv:"-"`
+	}
+*/
+package csvconv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/RangelReale/rprim"
+)
+
+// Policy to apply to empty CSV cells when decoding.
+type EmptyPolicy int
+
+const (
+	// Empty cells are converted as an empty string
+	EMPTY_AS_STRING EmptyPolicy = iota
+	// Empty cells are converted as a nil value, following the nil rules of the rprim.Config
+	EMPTY_AS_NIL
+)
+
+// Error of a CSV cell conversion.
+type Error struct {
+	// Row number, starting at 1 (the header row).
+	Row int
+	// Column number, starting at 1.
+	Column int
+	// Column header name.
+	Header string
+	// Underlining conversion error.
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("Error at row %d, column %d (%s): %v", e.Row, e.Column, e.Header, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+type fieldInfo struct {
+	name   string
+	index  []int
+	config *rprim.Config
+}
+
+// Returns the list of mapped fields of the struct type, with the field configuration
+// from the "rprim" tag applied.
+func structFields(t reflect.Type, config *rprim.Config) ([]*fieldInfo, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Value must be a struct, is %s", t.Kind().String())
+	}
+	var ret []*fieldInfo
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("csv"); ok {
+			tag = strings.Split(tag, ",")[0]
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fconfig, err := config.FieldConfig(f)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, &fieldInfo{name: name, index: f.Index, config: fconfig})
+	}
+	return ret, nil
+}
@@ -0,0 +1,141 @@
+package csvconv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/RangelReale/rprim"
+)
+
+type testItem struct {
+	ID     int      `csv:"id"`
+	Name   string   `csv:"name"`
+	Price  *float64 `csv:"price"`
+	Amount uint8    `csv:"amount"`
+	Ignore string   `csv:"-"`
+}
+
+func TestDecode(t *testing.T) {
+	data := "id,name,price,amount,extra\n1,first,10.5,3,x\n2,second,,4,y\n"
+
+	d := NewDecoder(csv.NewReader(strings.NewReader(data)), nil)
+	d.EmptyPolicy = EMPTY_AS_NIL
+
+	var items []testItem
+	for {
+		var item testItem
+		err := d.Decode(&item)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, item)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+	if items[0].ID != 1 || items[0].Name != "first" || items[0].Price == nil || *items[0].Price != 10.5 || items[0].Amount != 3 {
+		t.Fatalf("Invalid first item: %+v", items[0])
+	}
+	if items[1].ID != 2 || items[1].Name != "second" || items[1].Price != nil || items[1].Amount != 4 {
+		t.Fatalf("Invalid second item: %+v", items[1])
+	}
+}
+
+func TestDecodeEmptyNil(t *testing.T) {
+	data := "id,name\n,first\n"
+
+	// nil to zero value not allowed
+	d := NewDecoder(csv.NewReader(strings.NewReader(data)), nil)
+	d.EmptyPolicy = EMPTY_AS_NIL
+	var item testItem
+	if err := d.Decode(&item); err == nil {
+		t.Fatal("Expected error converting empty cell to int")
+	}
+
+	// nil to zero value allowed
+	d = NewDecoder(csv.NewReader(strings.NewReader(data)),
+		rprim.NewConfig().AddFlags(rprim.COP_ALLOW_NIL_TO_ZERO_VALUE))
+	d.EmptyPolicy = EMPTY_AS_NIL
+	if err := d.Decode(&item); err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != 0 || item.Name != "first" {
+		t.Fatalf("Invalid item: %+v", item)
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	data := "id,name\n1,first\nx,second\n"
+
+	d := NewDecoder(csv.NewReader(strings.NewReader(data)), nil)
+	var item testItem
+	if err := d.Decode(&item); err != nil {
+		t.Fatal(err)
+	}
+
+	err := d.Decode(&item)
+	var cerr *Error
+	if !errors.As(err, &cerr) {
+		t.Fatalf("Expected conversion error, got %v", err)
+	}
+	if cerr.Row != 3 || cerr.Column != 1 || cerr.Header != "id" || cerr.Err == nil {
+		t.Fatalf("Invalid error: %+v", cerr)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	price := 10.5
+	var buf bytes.Buffer
+
+	e := NewEncoder(csv.NewWriter(&buf), rprim.NewConfig())
+	if err := e.Encode(&testItem{ID: 1, Name: "first", Price: &price, Amount: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Encode(testItem{ID: 2, Name: "second", Amount: 4}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "id,name,price,amount\n1,first,10.500000,3\n2,second,,4\n"
+	if buf.String() != expected {
+		t.Fatalf("Invalid CSV output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestDecodeScale(t *testing.T) {
+	type money struct {
+		Amount int64 `csv:"amount" rprim:",scale=2"`
+	}
+
+	data := "amount\n12.34\n"
+	d := NewDecoder(csv.NewReader(strings.NewReader(data)), nil)
+	var m money
+	if err := d.Decode(&m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Amount != 1234 {
+		t.Fatalf("Amount should be 1234, is %d", m.Amount)
+	}
+
+	var buf bytes.Buffer
+	e := NewEncoder(csv.NewWriter(&buf), nil)
+	if err := e.Encode(&m); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != data {
+		t.Fatalf("Invalid CSV output: %s", buf.String())
+	}
+}
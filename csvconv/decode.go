@@ -0,0 +1,126 @@
+package csvconv
+
+import (
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/RangelReale/rprim"
+)
+
+// Decodes CSV records into structs.
+// The first record is used as the header.
+type Decoder struct {
+	Config      *rprim.Config
+	EmptyPolicy EmptyPolicy
+
+	r      *csv.Reader
+	header []string
+	row    int
+	fields map[reflect.Type][]*fieldInfo
+}
+
+// Creates a new decoder. If config is nil, a default one is used.
+func NewDecoder(r *csv.Reader, config *rprim.Config) *Decoder {
+	if config == nil {
+		config = rprim.NewConfig()
+	}
+	return &Decoder{
+		Config: config,
+		r:      r,
+		fields: make(map[reflect.Type][]*fieldInfo),
+	}
+}
+
+// Returns the header record, reading it if not read yet.
+func (d *Decoder) Header() ([]string, error) {
+	if d.header == nil {
+		header, err := d.r.Read()
+		if err != nil {
+			return nil, err
+		}
+		d.row++
+		d.header = header
+	}
+	return d.header, nil
+}
+
+// Reads the next record into out, which must be a pointer to a struct.
+// Returns io.EOF when there are no more records.
+func (d *Decoder) Decode(out interface{}) error {
+	header, err := d.Header()
+	if err != nil {
+		return err
+	}
+
+	outV := reflect.ValueOf(out)
+	if outV.Kind() != reflect.Ptr {
+		return errors.New("Decode target must be a pointer")
+	}
+	sv, err := rprim.EnsureUnderliningValue(outV)
+	if err != nil {
+		return err
+	}
+	fields, err := d.typeFields(sv.Type())
+	if err != nil {
+		return err
+	}
+
+	record, err := d.r.Read()
+	if err != nil {
+		return err
+	}
+	d.row++
+
+	for col, cell := range record {
+		if col >= len(header) {
+			break
+		}
+		field := findField(fields, header[col])
+		if field == nil {
+			continue
+		}
+
+		fv := sv.FieldByIndex(field.index)
+		src := reflect.ValueOf(cell)
+		if cell == "" && d.EmptyPolicy == EMPTY_AS_NIL {
+			src = reflect.ValueOf((*string)(nil))
+		}
+
+		cv, err := field.config.Convert(src, fv.Type())
+		if err != nil {
+			return &Error{Row: d.row, Column: col + 1, Header: header[col], Err: err}
+		}
+		fv.Set(cv)
+	}
+
+	return nil
+}
+
+func (d *Decoder) typeFields(t reflect.Type) ([]*fieldInfo, error) {
+	if fields, ok := d.fields[t]; ok {
+		return fields, nil
+	}
+	fields, err := structFields(t, d.Config)
+	if err != nil {
+		return nil, err
+	}
+	d.fields[t] = fields
+	return fields, nil
+}
+
+// Finds the field by name, preferring an exact match.
+func findField(fields []*fieldInfo, name string) *fieldInfo {
+	for _, f := range fields {
+		if f.name == name {
+			return f
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f
+		}
+	}
+	return nil
+}
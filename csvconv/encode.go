@@ -0,0 +1,82 @@
+package csvconv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/RangelReale/rprim"
+)
+
+// Encodes structs as CSV records.
+// The header is written before the first record.
+type Encoder struct {
+	Config *rprim.Config
+
+	w           *csv.Writer
+	row         int
+	typ         reflect.Type
+	fields      []*fieldInfo
+	wroteHeader bool
+}
+
+// Creates a new encoder. If config is nil, a default one is used.
+func NewEncoder(w *csv.Writer, config *rprim.Config) *Encoder {
+	if config == nil {
+		config = rprim.NewConfig()
+	}
+	return &Encoder{
+		Config: config,
+		w:      w,
+	}
+}
+
+// Writes the struct v as a CSV record. v can be a struct or any number of pointers to it.
+// Nil field values are written as empty cells.
+func (e *Encoder) Encode(v interface{}) error {
+	sv := rprim.UnderliningValue(reflect.ValueOf(v))
+	if !sv.IsValid() || rprim.UnderliningValueIsNil(reflect.ValueOf(v)) {
+		return errors.New("Cannot encode a nil value")
+	}
+	if !e.wroteHeader {
+		fields, err := structFields(sv.Type(), e.Config)
+		if err != nil {
+			return err
+		}
+		header := make([]string, len(fields))
+		for i, f := range fields {
+			header[i] = f.name
+		}
+		if err := e.w.Write(header); err != nil {
+			return err
+		}
+		e.row++
+		e.typ = sv.Type()
+		e.fields = fields
+		e.wroteHeader = true
+	} else if sv.Type() != e.typ {
+		return fmt.Errorf("All encoded values must be of type %s, got %s", e.typ.String(), sv.Type().String())
+	}
+	e.row++
+
+	record := make([]string, len(e.fields))
+	for i, f := range e.fields {
+		fv := sv.FieldByIndex(f.index)
+		if rprim.UnderliningValueIsNil(fv) {
+			continue
+		}
+		str, err := f.config.ConvertToString(fv)
+		if err != nil {
+			return &Error{Row: e.row, Column: i + 1, Header: f.name, Err: err}
+		}
+		record[i] = str
+	}
+	return e.w.Write(record)
+}
+
+// Flushes the underlining writer.
+func (e *Encoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
@@ -6,6 +6,23 @@ even if in different number.
 
 For string targets, it is possible to convert a printf/scanf format.
 
+Containers
+
+Slices, arrays, maps and structs are converted element by element, using the same rules for each element:
+
+  - slices and arrays are converted to slices or arrays; array destinations must be at least as long as
+    the source, and the remaining items are zero
+  - maps are converted to maps, converting the keys and the values
+  - structs are converted to structs by exported field name; fields missing in either side are skipped,
+    and at least one field must match. Structs with identical layouts are converted directly, keeping the
+    unexported fields
+  - errors are returned as a PathError with the path of the element, like "[2].Name", or all of them as
+    Errors with COP_COLLECT_ERRORS
+
+CanConvert and Explain check the elements of nested containers. Recursive types are only checked until a
+type pair repeats, the rest is checked when converting. Big numbers, database/sql nullable types and
+single-field wrapper structs inside containers are also only checked when converting.
+
 Examples
 
 String helpers:
@@ -0,0 +1,200 @@
+package rprim
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Error returned when a required environment variable is not set.
+var ErrEnvRequired = errors.New("Required environment variable not set")
+
+// Optional parameters for environment loading.
+type EnvOptions struct {
+	// Prefix added to all variable names.
+	Prefix string
+	// Function used to read variables, defaults to os.LookupEnv.
+	Lookup func(name string) (string, bool)
+}
+
+// Error of a single environment variable.
+type EnvError struct {
+	Name  string
+	Field string
+	Err   error
+}
+
+func (e *EnvError) Error() string {
+	return fmt.Sprintf("Error loading environment variable %s into field %s: %v", e.Name, e.Field, e.Err)
+}
+
+func (e *EnvError) Unwrap() error {
+	return e.Err
+}
+
+// List of all environment variable errors.
+type EnvErrors []*EnvError
+
+func (e EnvErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, ee := range e {
+		msgs[i] = ee.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e EnvErrors) Unwrap() []error {
+	ret := make([]error, len(e))
+	for i, ee := range e {
+		ret[i] = ee
+	}
+	return ret
+}
+
+// Loads environment variables into the struct pointed by out.
+// Fields are read from the variable named in the "env" tag, using the "default" tag if not set.
+// The variable is required if the "env" tag contains the "required" option, or if the
+// "required" tag is "true".
+// Struct fields (or pointers to structs, which are always allocated) are loaded recursively, using the
+// "env" tag as a prefix. Struct types that are already being loaded, like in linked lists, are skipped.
+// All errors are returned together as EnvErrors.
+func (c *Config) LoadEnv(out interface{}, opts *EnvOptions) error {
+	if opts == nil {
+		opts = &EnvOptions{}
+	}
+	lookup := opts.Lookup
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	ov := reflect.ValueOf(out)
+	if ov.Kind() != reflect.Ptr || ov.IsNil() {
+		return errors.New("LoadEnv target must be a non-nil pointer")
+	}
+	sv, err := EnsureUnderliningValue(ov)
+	if err != nil {
+		return err
+	}
+	if sv.Kind() != reflect.Struct {
+		return fmt.Errorf("LoadEnv target must be a struct, is %s", sv.Kind().String())
+	}
+
+	var errs EnvErrors
+	c.loadEnvStruct(sv, opts.Prefix, "", lookup, map[reflect.Type]bool{sv.Type(): true}, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Struct types being loaded are stored in visiting, to stop at self-referencing types.
+func (c *Config) loadEnvStruct(sv reflect.Value, prefix, path string, lookup func(string) (string, bool),
+	visiting map[reflect.Type]bool, errs *EnvErrors) {
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		f := st.Field(i)
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		fieldPath := path + f.Name
+
+		tag, hasTag := f.Tag.Lookup("env")
+		if hasTag && tag == "-" {
+			continue
+		}
+		tagParts := strings.Split(tag, ",")
+		name := tagParts[0]
+		required := f.Tag.Get("required") == "true"
+		for _, opt := range tagParts[1:] {
+			if opt == "required" {
+				required = true
+			}
+		}
+
+		fv := sv.Field(i)
+
+		// nested struct
+		if c.envNested(f.Type) {
+			ut := UnderliningType(f.Type)
+			if visiting[ut] {
+				continue
+			}
+			last, err := EnsureUnderliningValue(fv)
+			if err != nil {
+				*errs = append(*errs, &EnvError{Name: prefix + name, Field: fieldPath, Err: err})
+				continue
+			}
+			visiting[ut] = true
+			c.loadEnvStruct(last, prefix+name, fieldPath+".", lookup, visiting, errs)
+			delete(visiting, ut)
+			continue
+		}
+
+		if name == "" {
+			continue
+		}
+		name = prefix + name
+
+		value, ok := lookup(name)
+		if !ok {
+			if required {
+				*errs = append(*errs, &EnvError{Name: name, Field: fieldPath, Err: ErrEnvRequired})
+				continue
+			}
+			value, ok = f.Tag.Lookup("default")
+			if !ok {
+				continue
+			}
+		}
+
+		last, err := EnsureUnderliningValue(fv)
+		if err != nil {
+			*errs = append(*errs, &EnvError{Name: name, Field: fieldPath, Err: err})
+			continue
+		}
+		fconfig, err := c.FieldConfig(f)
+		if err != nil {
+			*errs = append(*errs, &EnvError{Name: name, Field: fieldPath, Err: err})
+			continue
+		}
+		if !fconfig.CanConvert(t_string, last.Type()) && envTextUnmarshaler(last.Type()) {
+			if err := last.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value)); err != nil {
+				*errs = append(*errs, &EnvError{Name: name, Field: fieldPath, Err: err})
+			}
+			continue
+		}
+		cv, err := fconfig.Convert(reflect.ValueOf(value), last.Type())
+		if err != nil {
+			*errs = append(*errs, &EnvError{Name: name, Field: fieldPath, Err: err})
+			continue
+		}
+		last.Set(cv)
+	}
+}
+
+var typeTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Checks if the type is loaded from a single variable using encoding.TextUnmarshaler, like time.Time.
+func envTextUnmarshaler(t reflect.Type) bool {
+	return reflect.PtrTo(UnderliningType(t)).Implements(typeTextUnmarshaler)
+}
+
+// Checks if the field type is a struct whose fields are loaded from their own variables. Structs that
+// can be loaded from a single variable, like big numbers, time.Time and single-field wrappers, are
+// only walked if any of their fields has an env tag.
+func (c *Config) envNested(t reflect.Type) bool {
+	ut := UnderliningType(t)
+	if ut.Kind() != reflect.Struct || IsSQLNullType(ut) || IsBigType(ut) {
+		return false
+	}
+	for i := 0; i < ut.NumField(); i++ {
+		if _, ok := ut.Field(i).Tag.Lookup("env"); ok {
+			return true
+		}
+	}
+	return !c.CanConvert(t_string, t) && !envTextUnmarshaler(t)
+}
@@ -0,0 +1,149 @@
+package rprim
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func testEnvLookup(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+}
+
+type testEnvDB struct {
+	Host string `env:"HOST" default:"localhost"`
+	Port *int   `env:"PORT"`
+}
+
+type testEnvConfig struct {
+	Name    string     `env:"NAME,required"`
+	Workers **uint8    `env:"WORKERS"`
+	Rate    float64    `env:"RATE" default:"1.5"`
+	Level   int        `env:"LEVEL" required:"true"`
+	DB      *testEnvDB `env:"DB_"`
+	Ignored string
+}
+
+func TestLoadEnv(t *testing.T) {
+	var cfg testEnvConfig
+	err := LoadEnv(&cfg, &EnvOptions{
+		Prefix: "APP_",
+		Lookup: testEnvLookup(map[string]string{
+			"APP_NAME":    "svc",
+			"APP_WORKERS": "8",
+			"APP_LEVEL":   "3",
+			"APP_DB_PORT": "5432",
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Name != "svc" || cfg.Workers == nil || **cfg.Workers != 8 || cfg.Rate != 1.5 || cfg.Level != 3 {
+		t.Fatalf("Invalid loaded config: %+v", cfg)
+	}
+	if cfg.DB == nil || cfg.DB.Host != "localhost" || cfg.DB.Port == nil || *cfg.DB.Port != 5432 {
+		t.Fatalf("Invalid loaded nested config: %+v", cfg.DB)
+	}
+}
+
+func TestLoadEnvErrors(t *testing.T) {
+	var cfg testEnvConfig
+	err := LoadEnv(&cfg, &EnvOptions{
+		Lookup: testEnvLookup(map[string]string{
+			"WORKERS": "x",
+			"DB_PORT": "y",
+		}),
+	})
+
+	eerr, ok := err.(EnvErrors)
+	if !ok {
+		t.Fatalf("Expected EnvErrors, got %v", err)
+	}
+	if len(eerr) != 4 {
+		t.Fatalf("Expected 4 errors, got %d: %v", len(eerr), eerr)
+	}
+	if !errors.Is(err, ErrEnvRequired) {
+		t.Fatal("Expected required variable error")
+	}
+	if eerr[3].Name != "DB_PORT" || eerr[3].Field != "DB.Port" {
+		t.Fatalf("Invalid nested error: %v", eerr[3])
+	}
+}
+
+type testEnvLevel struct {
+	Value string `env:"VALUE"`
+}
+
+type testEnvValues struct {
+	Total   big.Int      `env:"TOTAL"`
+	Limit   *big.Int     `env:"LIMIT"`
+	Started time.Time    `env:"STARTED"`
+	User    testUserID   `env:"USER"`
+	Level   testEnvLevel `env:"LEVEL_"`
+}
+
+func TestLoadEnvValueStructs(t *testing.T) {
+	var cfg testEnvValues
+	err := LoadEnv(&cfg, &EnvOptions{
+		Lookup: testEnvLookup(map[string]string{
+			"TOTAL":       "123456789012345678901234567890",
+			"LIMIT":       "-5",
+			"STARTED":     "2024-05-06T07:08:09Z",
+			"USER":        "42",
+			"LEVEL_VALUE": "debug",
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Total.String() != "123456789012345678901234567890" {
+		t.Fatalf("Invalid big.Int: %s", cfg.Total.String())
+	}
+	if cfg.Limit == nil || cfg.Limit.Int64() != -5 {
+		t.Fatalf("Invalid *big.Int: %v", cfg.Limit)
+	}
+	if !cfg.Started.Equal(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)) {
+		t.Fatalf("Invalid time.Time: %v", cfg.Started)
+	}
+	if cfg.User.V != 42 {
+		t.Fatalf("Invalid wrapper: %+v", cfg.User)
+	}
+	if cfg.Level.Value != "debug" {
+		t.Fatalf("Invalid nested struct: %+v", cfg.Level)
+	}
+
+	err = LoadEnv(&cfg, &EnvOptions{
+		Lookup: testEnvLookup(map[string]string{"STARTED": "yesterday"}),
+	})
+	var envErrs EnvErrors
+	if !errors.As(err, &envErrs) || len(envErrs) != 1 || envErrs[0].Name != "STARTED" {
+		t.Fatalf("Expected error for STARTED, got %v", err)
+	}
+}
+
+type testEnvNode struct {
+	Name string       `env:"NAME"`
+	Next *testEnvNode `env:"NEXT_"`
+}
+
+func TestLoadEnvRecursive(t *testing.T) {
+	var node testEnvNode
+	err := LoadEnv(&node, &EnvOptions{
+		Lookup: testEnvLookup(map[string]string{
+			"NAME":      "first",
+			"NEXT_NAME": "second",
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Name != "first" || node.Next != nil {
+		t.Fatalf("Invalid node: %+v", node)
+	}
+}
@@ -0,0 +1,59 @@
+package rprim
+
+import (
+	"strings"
+)
+
+// Error of a conversion nested inside slices, maps or structs, with the path to the failing element,
+// like ".Items[3].Price".
+type PathError struct {
+	Path string
+	Err  error
+}
+
+func (e *PathError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// List of conversion errors, returned when COP_COLLECT_ERRORS is set.
+// Supports the same unwrapping as errors.Join.
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// Adds the path prefix to the error, or to all errors of a list.
+func withPath(prefix string, err error) error {
+	switch perr := err.(type) {
+	case *PathError:
+		return &PathError{Path: prefix + perr.Path, Err: perr.Err}
+	case Errors:
+		ret := make(Errors, len(perr))
+		for i, e := range perr {
+			ret[i] = withPath(prefix, e)
+		}
+		return ret
+	}
+	return &PathError{Path: prefix, Err: err}
+}
+
+// Appends the error to the list, flattening lists.
+func appendErrors(errs Errors, err error) Errors {
+	if el, ok := err.(Errors); ok {
+		return append(errs, el...)
+	}
+	return append(errs, err)
+}
@@ -0,0 +1,204 @@
+package rprim
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Strategy used to convert between two types.
+type ConvertStrategy int
+
+const (
+	// The conversion is not supported
+	STRATEGY_INVALID ConvertStrategy = iota
+	// The value is assigned directly
+	STRATEGY_DIRECT
+	// The value is assigned directly, with pointer or interface indirections in either side
+	STRATEGY_DIRECT_POINTER
+	// The value is assigned to an interface destination
+	STRATEGY_INTERFACE
+	// Conversion between numeric kinds (int, uint, float, complex)
+	STRATEGY_NUMERIC
+	// The value is formatted as a string
+	STRATEGY_FORMAT
+	// The value is parsed from a string
+	STRATEGY_PARSE
+	// Conversion between string and []byte or []rune
+	STRATEGY_SLICE
+	// The value is wrapped in a database/sql nullable type
+	STRATEGY_SQL_NULL_WRAP
+	// The value is unwrapped from a database/sql nullable type
+	STRATEGY_SQL_NULL_UNWRAP
+	// Conversion from or to a math/big number
+	STRATEGY_BIG
+	// Element by element conversion of slices, arrays, maps or structs
+	STRATEGY_CONTAINER
+	// The value is wrapped in or unwrapped from a single-field struct
+	STRATEGY_WRAPPER
+	// The source is nil, and the result is the destination zero value
+	STRATEGY_NIL
+	// The conversion always returns an error
+	STRATEGY_ERROR
+	// The source is an interface, and the conversion depends on its contents at runtime
+	STRATEGY_DYNAMIC
+)
+
+var strategyNames = map[ConvertStrategy]string{
+	STRATEGY_INVALID:         "invalid",
+	STRATEGY_DIRECT:          "direct",
+	STRATEGY_DIRECT_POINTER:  "pointer-direct",
+	STRATEGY_INTERFACE:       "interface",
+	STRATEGY_NUMERIC:         "numeric",
+	STRATEGY_FORMAT:          "format",
+	STRATEGY_PARSE:           "parse",
+	STRATEGY_SLICE:           "slice",
+	STRATEGY_SQL_NULL_WRAP:   "sql-null-wrap",
+	STRATEGY_SQL_NULL_UNWRAP: "sql-null-unwrap",
+	STRATEGY_BIG:             "big",
+	STRATEGY_CONTAINER:       "container",
+	STRATEGY_WRAPPER:         "wrapper",
+	STRATEGY_NIL:             "nil",
+	STRATEGY_ERROR:           "error",
+	STRATEGY_DYNAMIC:         "dynamic",
+}
+
+func (s ConvertStrategy) String() string {
+	if name, ok := strategyNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("strategy(%d)", int(s))
+}
+
+var flagNames = []struct {
+	flag uint
+	name string
+}{
+	{COP_ALLOW_NIL_TO_ZERO_VALUE, "COP_ALLOW_NIL_TO_ZERO_VALUE"},
+	{COP_ALLOW_STRING_TO_SLICE, "COP_ALLOW_STRING_TO_SLICE"},
+	{COP_ALLOW_SLICE_TO_SRING, "COP_ALLOW_SLICE_TO_SRING"},
+	{COP_LENIENT_NUMERIC_STRING, "COP_LENIENT_NUMERIC_STRING"},
+	{COP_COLLECT_ERRORS, "COP_COLLECT_ERRORS"},
+	{COP_ZERO_TO_NIL, "COP_ZERO_TO_NIL"},
+}
+
+// Returns the names of the flags, separated by "|".
+func FlagsString(flags uint) string {
+	var names []string
+	for _, fn := range flagNames {
+		if flags&fn.flag == fn.flag {
+			names = append(names, fn.name)
+			flags &^= fn.flag
+		}
+	}
+	if flags != 0 {
+		names = append(names, fmt.Sprintf("0x%x", flags))
+	}
+	return strings.Join(names, "|")
+}
+
+// Description of how a conversion between two types is done.
+type ConvertExplanation struct {
+	SrcType reflect.Type
+	DstType reflect.Type
+	// Strategy used for non-nil source values
+	Strategy ConvertStrategy
+	// Flags consulted to select the strategy
+	Flags uint
+	// Strategy used for nil source values, STRATEGY_INVALID if the source type is not nillable
+	NilStrategy ConvertStrategy
+	// Flags consulted to select the nil strategy
+	NilFlags uint
+}
+
+func (e *ConvertExplanation) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s -> %s: %s", typeString(e.SrcType), typeString(e.DstType), e.Strategy.String())
+	if e.Flags != 0 {
+		fmt.Fprintf(&b, " (flags: %s)", FlagsString(e.Flags))
+	}
+	if e.NilStrategy != STRATEGY_INVALID {
+		fmt.Fprintf(&b, ", nil: %s", e.NilStrategy.String())
+		if e.NilFlags != 0 {
+			fmt.Fprintf(&b, " (flags: %s)", FlagsString(e.NilFlags))
+		}
+	}
+	return b.String()
+}
+
+func typeString(t reflect.Type) string {
+	if t == nil {
+		return "nil"
+	}
+	return t.String()
+}
+
+// Checks if a value of type srcType can be converted to dstType, without needing a value.
+// If srcType is an interface, returns true as the conversion depends on the value at runtime.
+// Returns false if any of the types is nil.
+func (c Config) CanConvert(srcType, dstType reflect.Type) bool {
+	if srcType == nil || dstType == nil {
+		return false
+	}
+	cop, strategy, _ := c.convertOp(typeSource(srcType), dstType)
+	return cop != nil || strategy == STRATEGY_DYNAMIC
+}
+
+// Describes how a value of type srcType is converted to dstType, without needing a value.
+// The strategy is STRATEGY_INVALID if any of the types is nil.
+func (c Config) Explain(srcType, dstType reflect.Type) *ConvertExplanation {
+	ret := &ConvertExplanation{
+		SrcType: srcType,
+		DstType: dstType,
+	}
+	if srcType == nil || dstType == nil {
+		return ret
+	}
+	src := typeSource(srcType)
+	_, ret.Strategy, ret.Flags = c.convertOp(src, dstType)
+	if srcType.Kind() == reflect.Ptr || srcType.Kind() == reflect.Interface {
+		_, ret.NilStrategy, ret.NilFlags = c.convertOp(src.asNil(), dstType)
+	}
+	return ret
+}
+
+// Representative types of each supported kind.
+var supportedConversionTypes = []reflect.Type{
+	reflect.TypeOf(int(0)),
+	reflect.TypeOf(int8(0)),
+	reflect.TypeOf(int16(0)),
+	reflect.TypeOf(int32(0)),
+	reflect.TypeOf(int64(0)),
+	reflect.TypeOf(uint(0)),
+	reflect.TypeOf(uint8(0)),
+	reflect.TypeOf(uint16(0)),
+	reflect.TypeOf(uint32(0)),
+	reflect.TypeOf(uint64(0)),
+	reflect.TypeOf(uintptr(0)),
+	reflect.TypeOf(float32(0)),
+	reflect.TypeOf(float64(0)),
+	reflect.TypeOf(complex64(0)),
+	reflect.TypeOf(complex128(0)),
+	reflect.TypeOf(""),
+	reflect.TypeOf([]byte(nil)),
+	reflect.TypeOf([]rune(nil)),
+	reflect.TypeOf((*interface{})(nil)).Elem(),
+}
+
+// Returns the list of all supported conversions between the primitive kinds, using representative types
+// for each kind. The flags needed by each conversion are returned in the explanation.
+func (c Config) SupportedConversions() []*ConvertExplanation {
+	all := c
+	all.Flags |= COP_ALLOW_NIL_TO_ZERO_VALUE | COP_ALLOW_STRING_TO_SLICE | COP_ALLOW_SLICE_TO_SRING
+
+	var ret []*ConvertExplanation
+	for _, srcType := range supportedConversionTypes {
+		for _, dstType := range supportedConversionTypes {
+			e := all.Explain(srcType, dstType)
+			if e.Strategy != STRATEGY_INVALID {
+				ret = append(ret, e)
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,92 @@
+package rprim
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestCanConvert(t *testing.T) {
+	var pi **int
+	var iface interface{}
+
+	tests := []struct {
+		src, dst reflect.Type
+		expected bool
+	}{
+		{reflect.TypeOf(""), reflect.TypeOf(0.0), true},
+		{reflect.TypeOf(pi), reflect.TypeOf(""), true},
+		{reflect.TypeOf(1), reflect.TypeOf(pi), true},
+		{reflect.TypeOf(1), reflect.TypeOf(struct{}{}), false},
+		{reflect.TypeOf(complex64(0)), reflect.TypeOf(1), false},
+		{reflect.TypeOf(""), reflect.TypeOf([]byte(nil)), false},
+		{reflect.TypeOf(&iface).Elem(), reflect.TypeOf(1), true},
+		{reflect.TypeOf(""), reflect.TypeOf(sql.NullInt64{}), true},
+		{reflect.TypeOf(sql.NullString{}), reflect.TypeOf(pi), true},
+	}
+
+	for _, test := range tests {
+		if NewConfig().CanConvert(test.src, test.dst) != test.expected {
+			t.Fatalf("CanConvert from %s to %s should be %v", test.src.String(), test.dst.String(), test.expected)
+		}
+	}
+
+	if !NewConfig().AddFlags(COP_ALLOW_STRING_TO_SLICE).CanConvert(reflect.TypeOf(""), reflect.TypeOf([]byte(nil))) {
+		t.Fatal("CanConvert from string to []byte should be allowed by flag")
+	}
+
+	if NewConfig().CanConvert(nil, reflect.TypeOf(1)) || NewConfig().CanConvert(reflect.TypeOf(1), nil) {
+		t.Fatal("CanConvert with nil types should be false")
+	}
+}
+
+func TestExplain(t *testing.T) {
+	var pi *int
+
+	e := Explain(reflect.TypeOf(pi), reflect.TypeOf(""))
+	if e.Strategy != STRATEGY_FORMAT || e.NilStrategy != STRATEGY_ERROR || e.NilFlags != COP_ALLOW_NIL_TO_ZERO_VALUE {
+		t.Fatalf("Invalid explanation: %s", e.String())
+	}
+
+	e = Explain(reflect.TypeOf(pi), reflect.TypeOf(pi))
+	if e.Strategy != STRATEGY_DIRECT_POINTER || e.NilStrategy != STRATEGY_NIL {
+		t.Fatalf("Invalid explanation: %s", e.String())
+	}
+
+	e = Explain(reflect.TypeOf(""), reflect.TypeOf([]rune(nil)))
+	if e.Strategy != STRATEGY_INVALID || e.Flags != COP_ALLOW_STRING_TO_SLICE {
+		t.Fatalf("Invalid explanation: %s", e.String())
+	}
+
+	expected := "*int -> string: format, nil: error (flags: COP_ALLOW_NIL_TO_ZERO_VALUE)"
+	if s := Explain(reflect.TypeOf(pi), reflect.TypeOf("")).String(); s != expected {
+		t.Fatalf("Explanation should be '%s', is '%s'", expected, s)
+	}
+
+	for _, e := range []*ConvertExplanation{Explain(nil, reflect.TypeOf("")), Explain(reflect.TypeOf(pi), nil)} {
+		if e.Strategy != STRATEGY_INVALID || e.NilStrategy != STRATEGY_INVALID {
+			t.Fatalf("Invalid explanation: %s", e.String())
+		}
+	}
+}
+
+func TestSupportedConversions(t *testing.T) {
+	found := false
+	for _, e := range SupportedConversions() {
+		if e.Strategy == STRATEGY_INVALID {
+			t.Fatalf("Invalid conversion returned: %s", e.String())
+		}
+		if e.SrcType.Kind() == reflect.Complex64 && e.DstType.Kind() == reflect.Int {
+			t.Fatal("Conversion from complex64 to int should not be supported")
+		}
+		if e.SrcType == reflect.TypeOf([]byte(nil)) && e.DstType.Kind() == reflect.String {
+			found = true
+			if e.Flags != COP_ALLOW_SLICE_TO_SRING {
+				t.Fatalf("Invalid flags for []byte to string: %s", FlagsString(e.Flags))
+			}
+		}
+	}
+	if !found {
+		t.Fatal("Conversion from []byte to string not found")
+	}
+}
@@ -0,0 +1,124 @@
+package rprim
+
+import (
+	"errors"
+	"flag"
+	"reflect"
+	"strings"
+)
+
+// Implements flag.Value (and pflag.Value) for a pointer to any value supported by the conversion.
+// Slice values are repeatable, each flag occurrence appends an item. The first occurrence
+// replaces the default value.
+type FlagValue struct {
+	Ptr    interface{}
+	Config *Config
+
+	changed bool
+}
+
+// Creates a new flag value for the pointer.
+func NewFlagValue(ptr interface{}) *FlagValue {
+	return NewConfig().FlagValue(ptr)
+}
+
+// Creates a new flag value for the pointer using this configuration.
+func (c *Config) FlagValue(ptr interface{}) *FlagValue {
+	return &FlagValue{
+		Ptr:    ptr,
+		Config: c,
+	}
+}
+
+// Registers a flag in the flag set backed by the pointer, using this configuration.
+func (c *Config) FlagVar(fs *flag.FlagSet, ptr interface{}, name, usage string) {
+	fs.Var(c.FlagValue(ptr), name, usage)
+}
+
+func (f *FlagValue) config() *Config {
+	if f.Config == nil {
+		return NewConfig()
+	}
+	return f.Config
+}
+
+func (f *FlagValue) target() (reflect.Value, error) {
+	pv := reflect.ValueOf(f.Ptr)
+	if pv.Kind() != reflect.Ptr || pv.IsNil() {
+		return reflect.Value{}, errors.New("Flag value must be a non-nil pointer")
+	}
+	return pv.Elem(), nil
+}
+
+// Set implements the flag.Value interface.
+func (f *FlagValue) Set(s string) error {
+	target, err := f.target()
+	if err != nil {
+		return err
+	}
+	c := f.config()
+	src := reflect.ValueOf(s)
+
+	if UnderliningTypeKind(target.Type()) == reflect.Slice {
+		last, err := EnsureUnderliningValue(target)
+		if err != nil {
+			return err
+		}
+		if !f.changed {
+			last.Set(reflect.MakeSlice(last.Type(), 0, 1))
+		}
+		cv, err := c.Convert(src, last.Type().Elem())
+		if err != nil {
+			return err
+		}
+		last.Set(reflect.Append(last, cv))
+	} else {
+		cv, err := c.Convert(src, target.Type())
+		if err != nil {
+			return err
+		}
+		target.Set(cv)
+	}
+	f.changed = true
+	return nil
+}
+
+// String implements the flag.Value interface.
+func (f *FlagValue) String() string {
+	if f == nil {
+		return ""
+	}
+	target, err := f.target()
+	if err != nil || UnderliningValueIsNil(target) {
+		return ""
+	}
+	c := f.config()
+
+	value := UnderliningValue(target)
+	if value.Kind() == reflect.Slice {
+		items := make([]string, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			str, err := c.ConvertToString(value.Index(i))
+			if err != nil {
+				return ""
+			}
+			items[i] = str
+		}
+		return strings.Join(items, ",")
+	}
+
+	str, err := c.ConvertToString(value)
+	if err != nil {
+		return ""
+	}
+	return str
+}
+
+// Type returns the name of the underlining type, implementing the pflag.Value interface.
+func (f *FlagValue) Type() string {
+	target, err := f.target()
+	if err != nil {
+		return ""
+	}
+	return UnderliningType(target.Type()).String()
+}
@@ -0,0 +1,56 @@
+package rprim
+
+import (
+	"flag"
+	"io"
+	"testing"
+)
+
+type testFlagLevel int
+
+func TestFlagVar(t *testing.T) {
+	var (
+		level testFlagLevel
+		rate  **float32
+		ids   = []uint16{1}
+	)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	FlagVar(fs, &level, "level", "level")
+	FlagVar(fs, &rate, "rate", "rate")
+	FlagVar(fs, &ids, "id", "ids")
+
+	err := fs.Parse([]string{"-level", "3", "-rate", "0.5", "-id", "10", "-id", "20"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if level != 3 {
+		t.Fatalf("Level should be 3, is %d", level)
+	}
+	if rate == nil || *rate == nil || **rate != 0.5 {
+		t.Fatal("Rate should be 0.5")
+	}
+	if len(ids) != 2 || ids[0] != 10 || ids[1] != 20 {
+		t.Fatalf("Ids should be [10 20], are %v", ids)
+	}
+
+	if s := fs.Lookup("id").Value.String(); s != "10,20" {
+		t.Fatalf("Ids string should be 10,20, is %s", s)
+	}
+	if s := fs.Lookup("level").Value.String(); s != "3" {
+		t.Fatalf("Level string should be 3, is %s", s)
+	}
+}
+
+func TestFlagVarError(t *testing.T) {
+	var level int
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	FlagVar(fs, &level, "level", "level")
+
+	if err := fs.Parse([]string{"-level", "x"}); err == nil {
+		t.Fatal("Expected error parsing invalid level")
+	}
+}
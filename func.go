@@ -0,0 +1,111 @@
+package rprim
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var typeError = reflect.TypeOf((*error)(nil)).Elem()
+
+// Returns a function of type targetFuncType that calls fn, converting each argument to the fn parameter
+// type and each fn result to the target result type.
+// If the last target result is an error, conversion errors and the error returned by fn (if its last
+// result is also an error) are returned there, with the other results set to their zero values.
+// Otherwise conversion errors and errors returned by fn cause a panic.
+// Variadic functions are supported only if both functions are variadic.
+func (c *Config) AdaptFunc(fn interface{}, targetFuncType reflect.Type) (reflect.Value, error) {
+	fv := reflect.ValueOf(fn)
+	if fv.Kind() != reflect.Func || fv.IsNil() {
+		return reflect.Value{}, errors.New("AdaptFunc source must be a non-nil function")
+	}
+	if targetFuncType.Kind() != reflect.Func {
+		return reflect.Value{}, fmt.Errorf("AdaptFunc target must be a function type, is %s", targetFuncType.String())
+	}
+	ft := fv.Type()
+
+	if ft.NumIn() != targetFuncType.NumIn() {
+		return reflect.Value{}, fmt.Errorf("Function %s has %d parameters, target %s has %d",
+			ft.String(), ft.NumIn(), targetFuncType.String(), targetFuncType.NumIn())
+	}
+	if ft.IsVariadic() != targetFuncType.IsVariadic() {
+		return reflect.Value{}, fmt.Errorf("Function %s and target %s must be both variadic or non-variadic",
+			ft.String(), targetFuncType.String())
+	}
+	for i := 0; i < ft.NumIn(); i++ {
+		if !c.canConvertElem(targetFuncType.In(i), ft.In(i)) {
+			return reflect.Value{}, fmt.Errorf("Invalid conversion of argument %d from %s to %s",
+				i, targetFuncType.In(i).String(), ft.In(i).String())
+		}
+	}
+
+	// trailing error results are not converted
+	fnOut, fnErr := funcResults(ft)
+	targetOut, targetErr := funcResults(targetFuncType)
+	if fnOut != targetOut {
+		return reflect.Value{}, fmt.Errorf("Function %s has %d results, target %s has %d",
+			ft.String(), fnOut, targetFuncType.String(), targetOut)
+	}
+	for i := 0; i < fnOut; i++ {
+		if !c.canConvertElem(ft.Out(i), targetFuncType.Out(i)) {
+			return reflect.Value{}, fmt.Errorf("Invalid conversion of result %d from %s to %s",
+				i, ft.Out(i).String(), targetFuncType.Out(i).String())
+		}
+	}
+
+	fail := func(err error) []reflect.Value {
+		if !targetErr {
+			panic(err)
+		}
+		ret := make([]reflect.Value, targetFuncType.NumOut())
+		for i := 0; i < targetOut; i++ {
+			ret[i] = reflect.Zero(targetFuncType.Out(i))
+		}
+		ret[targetOut] = reflect.ValueOf(&err).Elem()
+		return ret
+	}
+
+	return reflect.MakeFunc(targetFuncType, func(args []reflect.Value) []reflect.Value {
+		in := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			cv, err := c.convertElem(arg, ft.In(i))
+			if err != nil {
+				return fail(fmt.Errorf("Error converting argument %d: %w", i, err))
+			}
+			in[i] = cv
+		}
+
+		var out []reflect.Value
+		if ft.IsVariadic() {
+			out = fv.CallSlice(in)
+		} else {
+			out = fv.Call(in)
+		}
+
+		if fnErr && !out[fnOut].IsNil() {
+			return fail(out[fnOut].Interface().(error))
+		}
+
+		ret := make([]reflect.Value, targetFuncType.NumOut())
+		for i := 0; i < targetOut; i++ {
+			cv, err := c.convertElem(out[i], targetFuncType.Out(i))
+			if err != nil {
+				return fail(fmt.Errorf("Error converting result %d: %w", i, err))
+			}
+			ret[i] = cv
+		}
+		if targetErr {
+			ret[targetOut] = reflect.Zero(targetFuncType.Out(targetOut))
+		}
+		return ret
+	}), nil
+}
+
+// Returns the number of function results, not counting a trailing error, and whether there is one.
+func funcResults(t reflect.Type) (int, bool) {
+	n := t.NumOut()
+	if n > 0 && t.Out(n-1) == typeError {
+		return n - 1, true
+	}
+	return n, false
+}
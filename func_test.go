@@ -0,0 +1,90 @@
+package rprim
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAdaptFunc(t *testing.T) {
+	handler := func(a int32, b float64) (int64, error) {
+		if a < 0 {
+			return 0, errors.New("negative")
+		}
+		return int64(a) * int64(b), nil
+	}
+
+	var target func(a, b string) (string, error)
+	fv, err := AdaptFunc(handler, reflect.TypeOf(target))
+	if err != nil {
+		t.Fatal(err)
+	}
+	target = fv.Interface().(func(a, b string) (string, error))
+
+	ret, err := target("6", "7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ret != "42" {
+		t.Fatalf("Value should be 42, is %s", ret)
+	}
+
+	if _, err = target("x", "7"); err == nil || !strings.Contains(err.Error(), "argument 0") {
+		t.Fatalf("Expected argument error, got %v", err)
+	}
+
+	if _, err = target("-1", "7"); err == nil || err.Error() != "negative" {
+		t.Fatalf("Expected function error, got %v", err)
+	}
+}
+
+func TestAdaptFuncPanic(t *testing.T) {
+	fv, err := AdaptFunc(func(a uint8) uint8 { return a + 1 }, reflect.TypeOf(func(string) int { return 0 }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := fv.Interface().(func(string) int)
+	if f("9") != 10 {
+		t.Fatal("Value should be 10")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected panic")
+		}
+	}()
+	f("invalid")
+}
+
+func TestAdaptFuncVariadic(t *testing.T) {
+	sum := func(values ...int) int {
+		ret := 0
+		for _, v := range values {
+			ret += v
+		}
+		return ret
+	}
+	fv, err := AdaptFunc(sum, reflect.TypeOf(func(...string) string { return "" }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ret := fv.Interface().(func(...string) string)("1", "2", "3"); ret != "6" {
+		t.Fatalf("Value should be 6, is %s", ret)
+	}
+}
+
+func TestAdaptFuncInvalid(t *testing.T) {
+	if _, err := AdaptFunc(func(int) {}, reflect.TypeOf(func(string, string) {})); err == nil {
+		t.Fatal("Expected parameter count error")
+	}
+	if _, err := AdaptFunc(func(int) {}, reflect.TypeOf(func(complex64) {})); err == nil {
+		t.Fatal("Expected conversion error")
+	}
+	if _, err := AdaptFunc(func() (int, error) { return 0, nil }, reflect.TypeOf(func() {})); err == nil {
+		t.Fatal("Expected result count error")
+	}
+	if _, err := AdaptFunc(1, reflect.TypeOf(func() {})); err == nil {
+		t.Fatal("Expected non-function error")
+	}
+}
@@ -0,0 +1,218 @@
+package rprim
+
+import (
+	"database/sql"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+type (
+	fuzzString string
+	fuzzRune   int32
+	fuzzByte   uint8
+	fuzzRunes  []fuzzRune
+	fuzzBytes  []fuzzByte
+)
+
+var fuzzTypes = []reflect.Type{
+	reflect.TypeOf(int(0)), reflect.TypeOf(int8(0)), reflect.TypeOf(int16(0)), reflect.TypeOf(int32(0)),
+	reflect.TypeOf(int64(0)), reflect.TypeOf(uint(0)), reflect.TypeOf(uint8(0)), reflect.TypeOf(uint16(0)),
+	reflect.TypeOf(uint32(0)), reflect.TypeOf(uint64(0)), reflect.TypeOf(uintptr(0)),
+	reflect.TypeOf(float32(0)), reflect.TypeOf(float64(0)),
+	reflect.TypeOf(complex64(0)), reflect.TypeOf(complex128(0)),
+	reflect.TypeOf(""), reflect.TypeOf(fuzzString("")), reflect.TypeOf(true),
+	reflect.TypeOf([]byte(nil)), reflect.TypeOf([]rune(nil)), reflect.TypeOf(fuzzRunes(nil)),
+	reflect.TypeOf(fuzzBytes(nil)), reflect.TypeOf([]fuzzRune(nil)), reflect.TypeOf([]int(nil)),
+	reflect.TypeOf(map[string]int(nil)), reflect.TypeOf([2]string{}),
+	reflect.TypeOf(big.Int{}), reflect.TypeOf(big.Float{}), reflect.TypeOf(big.Rat{}),
+	reflect.TypeOf(sql.NullInt64{}), reflect.TypeOf(sql.NullString{}),
+	reflect.TypeOf(testUserID{}), reflect.TypeOf(struct{ A, B int }{}),
+	reflect.TypeOf((*interface{})(nil)).Elem(),
+}
+
+// Returns a value of the type built from the fuzz inputs, when the type allows it.
+func fuzzValue(t reflect.Type, s string, i int64, f float64) reflect.Value {
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(f)
+	case reflect.Complex64, reflect.Complex128:
+		v.SetComplex(complex(f, float64(i)))
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		v.SetBool(i%2 == 0)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Int32 {
+			runes := []rune(s)
+			v.Set(reflect.MakeSlice(t, len(runes), len(runes)))
+			for idx, r := range runes {
+				v.Index(idx).SetInt(int64(r))
+			}
+		} else if t.Elem().Kind() == reflect.Uint8 {
+			v.Set(reflect.MakeSlice(t, len(s), len(s)))
+			for idx := 0; idx < len(s); idx++ {
+				v.Index(idx).SetUint(uint64(s[idx]))
+			}
+		} else if t.Elem().Kind() == reflect.Int {
+			v.Set(reflect.ValueOf([]int{int(i), int(f)}))
+		}
+	case reflect.Map:
+		v.Set(reflect.ValueOf(map[string]int{s: int(i)}))
+	case reflect.Array:
+		v.Index(0).SetString(s)
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(s))
+	case reflect.Struct:
+		switch vi := v.Addr().Interface().(type) {
+		case *big.Int:
+			vi.SetInt64(i)
+		case *big.Float:
+			vi.SetFloat64(f)
+		case *big.Rat:
+			vi.SetFrac64(i, 7)
+		case *sql.NullInt64:
+			*vi = sql.NullInt64{Int64: i, Valid: i%2 == 0}
+		case *sql.NullString:
+			*vi = sql.NullString{String: s, Valid: i%2 == 0}
+		case *testUserID:
+			vi.V = i
+		}
+	}
+	return v
+}
+
+// Wraps the value in depth pointers, or in a nil pointer at the innermost level if nilInner is set.
+func fuzzPointer(v reflect.Value, depth int, nilInner bool) reflect.Value {
+	for d := 0; d < depth; d++ {
+		p := reflect.New(v.Type())
+		if d > 0 || !nilInner {
+			p.Elem().Set(v)
+		} else {
+			p = reflect.New(reflect.PtrTo(v.Type())).Elem()
+		}
+		v = p
+	}
+	return v
+}
+
+func fuzzType(t reflect.Type, depth int) reflect.Type {
+	for d := 0; d < depth; d++ {
+		t = reflect.PtrTo(t)
+	}
+	return t
+}
+
+func FuzzConvert(f *testing.F) {
+	f.Add(uint8(0), uint8(0), uint8(15), uint8(0), uint8(0), "109", int64(109), 109.0, uint8(0))
+	f.Add(uint8(19), uint8(1), uint8(15), uint8(0), uint8(0), "abc", int64(-1), -0.5, uint8(0))
+	f.Add(uint8(15), uint8(2), uint8(1), uint8(1), uint8(0), "12.9", int64(1<<40), 1e300, uint8(1))
+	f.Add(uint8(20), uint8(0), uint8(16), uint8(0), uint8(0), "xyz", int64(0), 0.0, uint8(32))
+	f.Add(uint8(10), uint8(1), uint8(0), uint8(0), uint8(0), "", int64(-5), -1.0, uint8(8))
+	f.Add(uint8(26), uint8(0), uint8(29), uint8(1), uint8(2), "1/3", int64(3), 0.333, uint8(63))
+	f.Add(uint8(13), uint8(3), uint8(0), uint8(0), uint8(1), "", int64(0), 0.0, uint8(1))
+
+	f.Fuzz(func(t *testing.T, srcKind, srcDepth, dstKind, dstDepth, modes uint8, s string, i int64, fl float64, flags uint8) {
+		srcType := fuzzTypes[int(srcKind)%len(fuzzTypes)]
+		dstType := fuzzType(fuzzTypes[int(dstKind)%len(fuzzTypes)], int(dstDepth%3))
+		src := fuzzPointer(fuzzValue(srcType, s, i, fl), int(srcDepth%4), modes&4 != 0)
+
+		c := NewConfig().SetFlags(uint(flags) & (COP_ALLOW_NIL_TO_ZERO_VALUE | COP_ALLOW_STRING_TO_SLICE |
+			COP_ALLOW_SLICE_TO_SRING | COP_LENIENT_NUMERIC_STRING | COP_COLLECT_ERRORS | COP_ZERO_TO_NIL))
+		c.Overflow = OverflowMode(modes % 3)
+		c.NaN = NaNPolicy((modes >> 3) % 4)
+		c.Interface = InterfaceMode((modes >> 5) % 3)
+
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("panic converting %s to %s: %v", src.Type().String(), dstType.String(), r)
+			}
+		}()
+
+		c.Explain(src.Type(), dstType)
+		cv, err := c.Convert(src, dstType)
+		// converters recover from panics, returning ErrPanic
+		if errors.Is(err, ErrPanic) {
+			t.Fatalf("converting %s to %s: %v", src.Type().String(), dstType.String(), err)
+		}
+		if err == nil && cv.IsValid() && cv.Type() != dstType {
+			t.Fatalf("converting %s to %s returned type %s", src.Type().String(), dstType.String(), cv.Type().String())
+		}
+		if _, err := c.ConvertToString(src); errors.Is(err, ErrPanic) {
+			t.Fatalf("converting %s to string: %v", src.Type().String(), err)
+		}
+	})
+}
+
+func TestConvertInvalidValue(t *testing.T) {
+	if _, err := Convert(reflect.Value{}, reflect.TypeOf(0)); err == nil {
+		t.Fatal("expected error converting invalid value")
+	}
+	if _, err := ConvertToString(reflect.Value{}); err == nil {
+		t.Fatal("expected error converting invalid value to string")
+	}
+	if cop := NewConfig().ConvertOp(reflect.ValueOf(1), reflect.Value{}); cop != nil {
+		t.Fatal("expected nil converter for invalid destination")
+	}
+}
+
+func TestConvertOpInvalidValue(t *testing.T) {
+	cop := NewConfig().ConvertOpType(reflect.ValueOf(1), reflect.TypeOf(""))
+	if _, err := cop(reflect.Value{}, reflect.TypeOf("")); err == nil {
+		t.Fatal("expected error converting invalid value")
+	}
+
+	panics := NewConfig().Use(func(next ConvertOpFunc) ConvertOpFunc {
+		return func(v reflect.Value, typ reflect.Type) (reflect.Value, error) {
+			panic("failed")
+		}
+	})
+	if _, err := panics.Convert(reflect.ValueOf(1), reflect.TypeOf("")); !errors.Is(err, ErrPanic) {
+		t.Fatalf("expected panic error, got %v", err)
+	}
+}
+
+func TestConvertNamedSlices(t *testing.T) {
+	cv, err := NewConfig().SetFlags(COP_ALLOW_SLICE_TO_SRING).Convert(reflect.ValueOf(fuzzRunes("héllo")), reflect.TypeOf(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.String() != "héllo" {
+		t.Fatalf("expected héllo, got %s", cv.String())
+	}
+
+	cv, err = NewConfig().SetFlags(COP_ALLOW_STRING_TO_SLICE).Convert(reflect.ValueOf("ab"), reflect.TypeOf([]fuzzRune(nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Len() != 2 || cv.Index(1).Int() != 'b' {
+		t.Fatalf("unexpected value %v", cv.Interface())
+	}
+
+	cv, err = Convert(reflect.ValueOf(uintptr(12)), reflect.TypeOf(uintptr(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Uint() != 12 {
+		t.Fatalf("expected 12, got %d", cv.Uint())
+	}
+}
+
+func TestConvertInnerNilPointer(t *testing.T) {
+	// only a nil source follows the nil rules, a nil inside the pointers is an error
+	var inner *int
+	src := reflect.ValueOf(&inner)
+	for _, c := range []*Config{NewConfig(), NewConfig().SetFlags(COP_ALLOW_NIL_TO_ZERO_VALUE)} {
+		for _, dstType := range []reflect.Type{reflect.TypeOf(0), reflect.TypeOf(""), reflect.TypeOf(inner)} {
+			if _, err := c.Convert(src, dstType); !errors.Is(err, ErrNilPointerChain) {
+				t.Fatalf("expected nil pointer chain error converting to %s, got %v", dstType.String(), err)
+			}
+		}
+	}
+}
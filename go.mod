@@ -0,0 +1,3 @@
+module github.com/RangelReale/rprim
+
+go 1.22
@@ -1,6 +1,8 @@
 package rprim
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"reflect"
 )
@@ -17,9 +19,15 @@ func ConvertToString(src reflect.Value) (string, error) {
 
 // Helper to convert between a value and a type.
 func (c *Config) Convert(src reflect.Value, dstType reflect.Type) (reflect.Value, error) {
+	if !src.IsValid() {
+		return reflect.Value{}, fmt.Errorf("Invalid conversion from invalid value to %s", typeString(dstType))
+	}
 	cop := c.ConvertOpType(src, dstType)
 	if cop == nil {
-		return reflect.Value{}, fmt.Errorf("Invalid conversion from %s to %s", src.Type().String(), dstType.String())
+		if _, err := SafeUnderliningValue(src, 0); err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.Value{}, fmt.Errorf("Invalid conversion from %s to %s", src.Type().String(), typeString(dstType))
 	}
 	cv, err := cop(src, dstType)
 	if err != nil {
@@ -32,8 +40,14 @@ func (c *Config) Convert(src reflect.Value, dstType reflect.Type) (reflect.Value
 func (c *Config) ConvertToString(src reflect.Value) (string, error) {
 	t_string := reflect.TypeOf("")
 
+	if !src.IsValid() {
+		return "", fmt.Errorf("Invalid conversion from invalid value to %s", "string")
+	}
 	cop := c.ConvertOpType(src, t_string)
 	if cop == nil {
+		if _, err := SafeUnderliningValue(src, 0); err != nil {
+			return "", err
+		}
 		return "", fmt.Errorf("Invalid conversion from %s to %s", src.Type().String(), "string")
 	}
 	cv, err := cop(src, t_string)
@@ -42,3 +56,58 @@ func (c *Config) ConvertToString(src reflect.Value) (string, error) {
 	}
 	return cv.String(), nil
 }
+
+// Helper to convert all items of a slice into another slice.
+func ConvertSlice(dst, src interface{}, opts *SliceOptions) error {
+	return NewConfig().ConvertSlice(dst, src, opts)
+}
+
+// Helper to load environment variables into a struct.
+func LoadEnv(out interface{}, opts *EnvOptions) error {
+	return NewConfig().LoadEnv(out, opts)
+}
+
+// Helper to register a flag in the flag set backed by the pointer.
+func FlagVar(fs *flag.FlagSet, ptr interface{}, name, usage string) {
+	NewConfig().FlagVar(fs, ptr, name, usage)
+}
+
+// Helper to check if a value of type srcType can be converted to dstType.
+func CanConvert(srcType, dstType reflect.Type) bool {
+	return NewConfig().CanConvert(srcType, dstType)
+}
+
+// Helper to describe how a value of type srcType is converted to dstType.
+func Explain(srcType, dstType reflect.Type) *ConvertExplanation {
+	return NewConfig().Explain(srcType, dstType)
+}
+
+// Helper to list all supported conversions between the primitive kinds.
+func SupportedConversions() []*ConvertExplanation {
+	return NewConfig().SupportedConversions()
+}
+
+// Helper to adapt a function to another function type, converting arguments and results.
+func AdaptFunc(fn interface{}, targetFuncType reflect.Type) (reflect.Value, error) {
+	return NewConfig().AdaptFunc(fn, targetFuncType)
+}
+
+// Helper to convert all elements received from a channel, returning a channel of dstType elements.
+func ConvertChan(ctx context.Context, src interface{}, dstType reflect.Type, opts *ChanOptions) (reflect.Value, error) {
+	return NewConfig().ConvertChan(ctx, src, dstType, opts)
+}
+
+// Helper to check if the values are semantically equal.
+func Equal(a, b interface{}, opts *CompareOptions) bool {
+	return NewConfig().Equal(a, b, opts)
+}
+
+// Helper to order the values semantically.
+func Compare(a, b interface{}, opts *CompareOptions) (int, error) {
+	return NewConfig().Compare(a, b, opts)
+}
+
+// Helper to return a comparable key for grouping semantically equal values.
+func Key(v interface{}) interface{} {
+	return NewConfig().Key(v, nil)
+}
@@ -0,0 +1,239 @@
+package rprim
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// How the concrete value stored in interface destinations is chosen.
+type InterfaceMode int
+
+const (
+	// The interface holds the source value, keeping its type
+	INTERFACE_KEEP InterfaceMode = iota
+	// The interface holds the dereferenced source value, converted to int64, uint64, float64, complex128,
+	// string or bool depending on its kind. Null database/sql wrappers are nil, valid ones hold their
+	// canonical value. Values of other kinds are only dereferenced.
+	INTERFACE_CANONICAL
+	// Same as INTERFACE_CANONICAL, also converting slices and arrays to []interface{} and maps to
+	// map[string]interface{} (or map[interface{}]interface{} if the keys are not strings), with canonical
+	// items. Byte slices are kept like strings. Nil items are stored as nil.
+	INTERFACE_CANONICAL_DEEP
+)
+
+var (
+	t_int64      = reflect.TypeOf(int64(0))
+	t_uint64     = reflect.TypeOf(uint64(0))
+	t_float64    = reflect.TypeOf(float64(0))
+	t_complex128 = reflect.TypeOf(complex128(0))
+	t_string     = reflect.TypeOf("")
+	t_bool       = reflect.TypeOf(false)
+
+	t_interface       = reflect.TypeOf((*interface{})(nil)).Elem()
+	t_interface_slice = reflect.SliceOf(t_interface)
+	t_string_map      = reflect.MapOf(t_string, t_interface)
+	t_interface_map   = reflect.MapOf(t_interface, t_interface)
+)
+
+// Returns the canonical type of the kind, or nil if the kind has none.
+func canonicalType(k reflect.Kind) reflect.Type {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return t_int64
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return t_uint64
+	case reflect.Float32, reflect.Float64:
+		return t_float64
+	case reflect.Complex64, reflect.Complex128:
+		return t_complex128
+	case reflect.String:
+		return t_string
+	case reflect.Bool:
+		return t_bool
+	default:
+		return nil
+	}
+}
+
+// Creates a new instance of the type like NewUnderliningValue, setting the last item to the value.
+// The type may be any chain of pointers ending in an interface, like **fmt.Stringer, in which case the
+// value must implement it.
+func WrapUnderliningValue(t reflect.Type, v reflect.Value) (reflect.Value, error) {
+	root, last, err := SafeNewUnderliningValue(t, 0)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if last.Kind() == reflect.Interface && !v.Type().Implements(last.Type()) {
+		return reflect.Value{}, fmt.Errorf("Type %s does not implement %s", v.Type().String(), last.Type().String())
+	}
+	if err := setUnderlining(last, v); err != nil {
+		return reflect.Value{}, err
+	}
+	return root, nil
+}
+
+// Returns the type that will be stored in the interface for the source, or nil if only known at runtime.
+func (c Config) interfaceValueType(src convertSource) reflect.Type {
+	if c.Interface == INTERFACE_KEEP {
+		if src.typ.Kind() == reflect.Interface {
+			return nil
+		}
+		return src.typ
+	}
+	return c.canonicalValueType(src.utype)
+}
+
+// Returns the canonical type stored for values of the type, or nil if only known at runtime.
+func (c Config) canonicalValueType(t reflect.Type) reflect.Type {
+	if ct := canonicalType(t.Kind()); ct != nil {
+		return ct
+	}
+	if t.Kind() == reflect.Interface || IsSQLNullType(t) {
+		return nil
+	}
+	if c.Interface == INTERFACE_CANONICAL_DEEP && !isByteSliceType(t) {
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array:
+			return t_interface_slice
+		case reflect.Map:
+			if c.canonicalValueType(UnderliningType(t.Key())) == t_string {
+				return t_string_map
+			}
+			return t_interface_map
+		}
+	}
+	return t
+}
+
+// Returns the concrete value to store in an interface, using the interface mode.
+// An invalid value is returned for nil.
+func (c Config) interfaceValue(v reflect.Value) (reflect.Value, error) {
+	if c.Interface == INTERFACE_KEEP {
+		if v.Kind() == reflect.Interface {
+			return v.Elem(), nil
+		}
+		return v, nil
+	}
+	return c.canonicalValue(v, nil)
+}
+
+// Returns the canonical value, an invalid value for nil.
+// Containers being converted are stored in visiting to detect cycles.
+func (c Config) canonicalValue(v reflect.Value, visiting map[canonicalVisit]bool) (reflect.Value, error) {
+	uv, err := SafeUnderliningValue(v, 0)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if (uv.Kind() == reflect.Ptr || uv.Kind() == reflect.Interface) && uv.IsNil() {
+		return reflect.Value{}, nil
+	}
+
+	if ct := canonicalType(uv.Kind()); ct != nil {
+		// same kind, always lossless
+		return uv.Convert(ct), nil
+	}
+
+	if IsSQLNullType(uv.Type()) {
+		if !uv.Field(1).Bool() {
+			return reflect.Value{}, nil
+		}
+		return c.canonicalValue(uv.Field(0), visiting)
+	}
+
+	if c.Interface != INTERFACE_CANONICAL_DEEP {
+		return uv, nil
+	}
+	if isByteSliceType(uv.Type()) {
+		if uv.IsNil() {
+			return reflect.Value{}, nil
+		}
+		return uv, nil
+	}
+
+	switch uv.Kind() {
+	case reflect.Slice, reflect.Map:
+		if uv.IsNil() {
+			return reflect.Value{}, nil
+		}
+		visit := canonicalVisit{uv.Type(), uv.Pointer()}
+		if visiting[visit] {
+			return reflect.Value{}, fmt.Errorf("Error converting %s: %w", uv.Type().String(), ErrIndirectionCycle)
+		}
+		if visiting == nil {
+			visiting = map[canonicalVisit]bool{}
+		}
+		visiting[visit] = true
+		defer delete(visiting, visit)
+	}
+
+	switch uv.Kind() {
+	case reflect.Slice, reflect.Array:
+		ret := reflect.MakeSlice(t_interface_slice, uv.Len(), uv.Len())
+		for i := 0; i < uv.Len(); i++ {
+			item, err := c.canonicalValue(uv.Index(i), visiting)
+			if err != nil {
+				return reflect.Value{}, withPath(fmt.Sprintf("[%d]", i), err)
+			}
+			if item.IsValid() {
+				ret.Index(i).Set(item)
+			}
+		}
+		return ret, nil
+	case reflect.Map:
+		ret := reflect.MakeMapWithSize(c.canonicalValueType(uv.Type()), uv.Len())
+		iter := uv.MapRange()
+		for iter.Next() {
+			key, err := c.canonicalValue(iter.Key(), visiting)
+			if err != nil {
+				return reflect.Value{}, withPath(fmt.Sprintf("[%v]", iter.Key().Interface()), err)
+			}
+			if !key.IsValid() {
+				key = reflect.Zero(ret.Type().Key())
+			} else if !key.Type().Comparable() {
+				return reflect.Value{}, fmt.Errorf("Canonical map key type %s is not comparable", key.Type().String())
+			}
+			item, err := c.canonicalValue(iter.Value(), visiting)
+			if err != nil {
+				return reflect.Value{}, withPath(fmt.Sprintf("[%v]", iter.Key().Interface()), err)
+			}
+			if !item.IsValid() {
+				item = reflect.Zero(t_interface)
+			}
+			ret.SetMapIndex(key, item)
+		}
+		return ret, nil
+	}
+	return uv, nil
+}
+
+// Checks if the type is a slice of bytes, which is binary data instead of a container.
+func isByteSliceType(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// A container being converted to its canonical value
+type canonicalVisit struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+// Returns the conversion function to a destination of interface type, or pointers to one.
+func (c Config) interfaceOp(src convertSource, dstType reflect.Type) ConvertOpFunc {
+	if dstType == nil {
+		return cvtAnyInterface
+	}
+	iface := UnderliningType(dstType)
+	if vt := c.interfaceValueType(src); vt != nil && !vt.Implements(iface) {
+		return nil
+	}
+	return func(v reflect.Value, typ reflect.Type) (reflect.Value, error) {
+		iv, err := c.interfaceValue(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if !iv.IsValid() {
+			return reflect.Zero(typ), nil
+		}
+		return WrapUnderliningValue(typ, iv)
+	}
+}
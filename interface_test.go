@@ -0,0 +1,215 @@
+package rprim
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type testStringer int
+
+func (s testStringer) String() string {
+	return fmt.Sprintf("S%d", int(s))
+}
+
+func TestConvertPointerToInterface(t *testing.T) {
+	var dst *interface{}
+	cv, err := Convert(reflect.ValueOf(uint16(12)), reflect.TypeOf(dst))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst = cv.Interface().(*interface{})
+	if v, ok := (*dst).(uint16); !ok || v != 12 {
+		t.Fatalf("Expected uint16 12, got %#v", *dst)
+	}
+
+	// pointer source is kept
+	i := 5
+	cv, err = Convert(reflect.ValueOf(&i), reflect.TypeOf(dst))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst = cv.Interface().(*interface{})
+	if v, ok := (*dst).(*int); !ok || *v != 5 {
+		t.Fatalf("Expected *int 5, got %#v", *dst)
+	}
+
+	// nil source
+	cv, err = Convert(reflect.ValueOf((*int)(nil)), reflect.TypeOf(dst))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cv.IsNil() {
+		t.Fatalf("Expected nil, got %#v", cv.Interface())
+	}
+}
+
+func TestConvertPointerToStringer(t *testing.T) {
+	var dst **fmt.Stringer
+	cv, err := Convert(reflect.ValueOf(testStringer(3)), reflect.TypeOf(dst))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst = cv.Interface().(**fmt.Stringer)
+	if s := (**dst).String(); s != "S3" {
+		t.Fatalf("Expected S3, got %s", s)
+	}
+
+	if NewConfig().ConvertOpType(reflect.ValueOf(3), reflect.TypeOf(dst)) != nil {
+		t.Fatal("int should not be convertible to fmt.Stringer")
+	}
+
+	// only known at runtime
+	var src interface{} = 3
+	if _, err := Convert(reflect.ValueOf(&src).Elem(), reflect.TypeOf(dst)); err == nil {
+		t.Fatal("Expected error converting int to fmt.Stringer")
+	}
+}
+
+func TestConvertInterfaceCanonical(t *testing.T) {
+	c := NewConfig().SetInterface(INTERFACE_CANONICAL)
+
+	i8 := int8(-4)
+	pi8 := &i8
+	tests := []struct {
+		src      interface{}
+		expected interface{}
+	}{
+		{&pi8, int64(-4)},
+		{uint8(4), uint64(4)},
+		{float32(1.5), float64(1.5)},
+		{complex64(2), complex128(2)},
+		{testStringer(7), int64(7)},
+		{true, true},
+		{testBool(true), true},
+		{testString("x"), "x"},
+		{[]int{1}, []int{1}},
+	}
+
+	var dst *interface{}
+	for _, test := range tests {
+		cv, err := c.Convert(reflect.ValueOf(test.src), reflect.TypeOf(dst))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dst = cv.Interface().(*interface{})
+		if !reflect.DeepEqual(*dst, test.expected) {
+			t.Fatalf("Expected %#v, got %#v", test.expected, *dst)
+		}
+	}
+
+	var s fmt.Stringer
+	if c.ConvertOpType(reflect.ValueOf(testStringer(7)), reflect.TypeOf(&s).Elem()) != nil {
+		t.Fatal("Canonical int64 should not be convertible to fmt.Stringer")
+	}
+}
+
+func TestWrapUnderliningValue(t *testing.T) {
+	i := 9
+	var dst *interface{}
+	cv, err := WrapUnderliningValue(reflect.TypeOf(dst), reflect.ValueOf(&i))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst = cv.Interface().(*interface{})
+	if v, ok := (*dst).(*int); !ok || *v != 9 {
+		t.Fatalf("Expected *int 9, got %#v", *dst)
+	}
+
+	var sdst **fmt.Stringer
+	if _, err := WrapUnderliningValue(reflect.TypeOf(sdst), reflect.ValueOf(i)); err == nil {
+		t.Fatal("Expected error wrapping int in fmt.Stringer")
+	}
+}
+
+func TestConvertInterfaceCanonicalPointers(t *testing.T) {
+	c := NewConfig().SetInterface(INTERFACE_CANONICAL)
+
+	i := 4
+	pi := &i
+	ppi := &pi
+	pppi := &ppi
+	var dst interface{}
+	cv, err := c.Convert(reflect.ValueOf(&pppi), reflect.TypeOf(&dst).Elem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := cv.Interface().(int64); !ok || v != 4 {
+		t.Fatalf("Expected int64 4, got %#v", cv.Interface())
+	}
+
+	cv, err = c.Convert(reflect.ValueOf(sql.NullString{String: "x", Valid: true}), reflect.TypeOf(&dst).Elem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := cv.Interface().(string); !ok || v != "x" {
+		t.Fatalf("Expected string x, got %#v", cv.Interface())
+	}
+
+	cv, err = c.Convert(reflect.ValueOf(sql.NullString{}), reflect.TypeOf(&dst).Elem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cv.IsNil() {
+		t.Fatalf("Expected nil, got %#v", cv.Interface())
+	}
+
+	// containers are kept
+	src := []int8{1, 2}
+	cv, err = c.Convert(reflect.ValueOf(src), reflect.TypeOf(&dst).Elem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cv.Interface().([]int8); !ok {
+		t.Fatalf("Expected []int8, got %#v", cv.Interface())
+	}
+}
+
+func TestConvertInterfaceCanonicalDeep(t *testing.T) {
+	c := NewConfig().SetInterface(INTERFACE_CANONICAL_DEEP)
+
+	f := float32(2.5)
+	src := map[testStringKey]interface{}{
+		"a": []interface{}{int8(1), &f, nil, (*int)(nil)},
+		"b": map[int]uint8{3: 4},
+		"c": [2]bool{true, false},
+		"d": sql.NullInt64{Int64: 5, Valid: true},
+		"e": []interface{}{[]byte("ab"), []byte(nil), testBool(false), "cd"},
+	}
+	expected := map[string]interface{}{
+		"a": []interface{}{int64(1), float64(2.5), nil, nil},
+		"b": map[interface{}]interface{}{int64(3): uint64(4)},
+		"c": []interface{}{true, false},
+		"d": int64(5),
+		"e": []interface{}{[]byte("ab"), nil, false, "cd"},
+	}
+
+	var dst interface{}
+	cv, err := c.Convert(reflect.ValueOf(&src), reflect.TypeOf(&dst).Elem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cv.Interface(), expected) {
+		t.Fatalf("Expected %#v, got %#v", expected, cv.Interface())
+	}
+
+	// cycles
+	cycle := map[string]interface{}{}
+	cycle["self"] = cycle
+	_, err = c.Convert(reflect.ValueOf(cycle), reflect.TypeOf(&dst).Elem())
+	if !errors.Is(err, ErrIndirectionCycle) {
+		t.Fatalf("Expected cycle error, got %v", err)
+	}
+	var perr *PathError
+	if !errors.As(err, &perr) || perr.Path != "[self]" {
+		t.Fatalf("Expected path error at [self], got %v", err)
+	}
+}
+
+type testStringKey string
+
+type testBool bool
+
+type testString string
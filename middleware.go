@@ -0,0 +1,22 @@
+package rprim
+
+// Middleware that wraps the conversion function selected by ConvertOpType.
+// It can change the source value before calling next, change the result or error after it, or
+// return an error without calling next to veto the conversion.
+// The conversion function is always selected using the original source value.
+// Middlewares run once per conversion, nested conversions like container elements don't call them again.
+type ConvertMiddleware func(next ConvertOpFunc) ConvertOpFunc
+
+// Adds middlewares to the configuration. The first added middleware is the outermost one.
+func (c *Config) Use(middlewares ...ConvertMiddleware) *Config {
+	c.Middlewares = append(c.Middlewares, middlewares...)
+	return c
+}
+
+// Wraps the conversion function with the configured middlewares.
+func (c Config) applyMiddlewares(cop ConvertOpFunc) ConvertOpFunc {
+	for i := len(c.Middlewares) - 1; i >= 0; i-- {
+		cop = c.Middlewares[i](cop)
+	}
+	return cop
+}
@@ -0,0 +1,119 @@
+package rprim
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMiddlewareInput(t *testing.T) {
+	trim := func(next ConvertOpFunc) ConvertOpFunc {
+		return func(v reflect.Value, typ reflect.Type) (reflect.Value, error) {
+			if v.Kind() == reflect.String {
+				v = reflect.ValueOf(strings.TrimSpace(v.String()))
+			}
+			return next(v, typ)
+		}
+	}
+
+	cv, err := NewConfig().Use(trim).Convert(reflect.ValueOf(" 12 "), reflect.TypeOf(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Int() != 12 {
+		t.Fatalf("Value should be 12, is %d", cv.Int())
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+	audit := func(name string) ConvertMiddleware {
+		return func(next ConvertOpFunc) ConvertOpFunc {
+			return func(v reflect.Value, typ reflect.Type) (reflect.Value, error) {
+				calls = append(calls, name+":before")
+				cv, err := next(v, typ)
+				calls = append(calls, name+":after")
+				return cv, err
+			}
+		}
+	}
+	upper := func(next ConvertOpFunc) ConvertOpFunc {
+		return func(v reflect.Value, typ reflect.Type) (reflect.Value, error) {
+			cv, err := next(v, typ)
+			if err != nil {
+				return cv, err
+			}
+			return reflect.ValueOf(strings.ToUpper(cv.String())), nil
+		}
+	}
+
+	str, err := NewConfig().Use(audit("a"), audit("b"), upper).ConvertToString(reflect.ValueOf(complex(1, 2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if str != "(1+2I)" {
+		t.Fatalf("Value should be (1+2I), is %s", str)
+	}
+
+	expected := "a:before,b:before,b:after,a:after"
+	if strings.Join(calls, ",") != expected {
+		t.Fatalf("Calls should be %s, are %s", expected, strings.Join(calls, ","))
+	}
+}
+
+func TestMiddlewareVeto(t *testing.T) {
+	errVeto := errors.New("negative values not allowed")
+	noNegative := func(next ConvertOpFunc) ConvertOpFunc {
+		return func(v reflect.Value, typ reflect.Type) (reflect.Value, error) {
+			if uv := UnderliningValue(v); uv.Kind() == reflect.Int && uv.Int() < 0 {
+				return reflect.Value{}, errVeto
+			}
+			return next(v, typ)
+		}
+	}
+
+	c := NewConfig().Use(noNegative)
+	if _, err := c.Convert(reflect.ValueOf(-1), reflect.TypeOf(uint(0))); err != errVeto {
+		t.Fatalf("Expected veto error, got %v", err)
+	}
+	if _, err := c.Convert(reflect.ValueOf(1), reflect.TypeOf(uint(0))); err != nil {
+		t.Fatal(err)
+	}
+
+	// duplicated config doesn't share the middleware list
+	d := c.Dup().Use(noNegative)
+	if len(c.Middlewares) != 1 || len(d.Middlewares) != 2 {
+		t.Fatal("Middlewares should not be shared")
+	}
+}
+
+func TestMiddlewareNested(t *testing.T) {
+	calls := 0
+	count := func(next ConvertOpFunc) ConvertOpFunc {
+		return func(v reflect.Value, typ reflect.Type) (reflect.Value, error) {
+			calls++
+			return next(v, typ)
+		}
+	}
+
+	cv, err := NewConfig().Use(count).Convert(reflect.ValueOf([]int{1, 2, 3}), reflect.TypeOf([]string(nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Len() != 3 || cv.Index(2).String() != "3" {
+		t.Fatalf("Unexpected value %v", cv.Interface())
+	}
+	if calls != 1 {
+		t.Fatalf("Middleware should be called once, was called %d times", calls)
+	}
+
+	panics := func(next ConvertOpFunc) ConvertOpFunc {
+		return func(v reflect.Value, typ reflect.Type) (reflect.Value, error) {
+			panic("middleware failed")
+		}
+	}
+	if _, err := NewConfig().Use(panics).Convert(reflect.ValueOf(1), reflect.TypeOf("")); err == nil {
+		t.Fatal("Expected error from middleware panic")
+	}
+}
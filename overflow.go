@@ -0,0 +1,134 @@
+package rprim
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// How to handle integer conversions where the value is out of the destination range.
+type OverflowMode int
+
+const (
+	// The value is truncated to the destination size, wrapping around
+	OVERFLOW_WRAP OverflowMode = iota
+	// Out of range values return an error
+	OVERFLOW_ERROR
+	// Out of range values are clamped to the destination minimum or maximum value
+	OVERFLOW_SATURATE
+)
+
+// How to handle NaN float values converted to integers, when the overflow mode is not OVERFLOW_WRAP.
+type NaNPolicy int
+
+const (
+	// NaN returns an error
+	NAN_ERROR NaNPolicy = iota
+	// NaN is converted to zero
+	NAN_ZERO
+	// NaN is converted to the destination minimum value
+	NAN_MIN
+	// NaN is converted to the destination maximum value
+	NAN_MAX
+)
+
+// The value is NaN
+var ErrNaN = errors.New("Value is NaN")
+
+// Returns the range of the integer type. The minimum is 0 for unsigned types.
+func intTypeRange(t reflect.Type) (min int64, max uint64, signed bool) {
+	bits := uint(t.Bits())
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return -1 << (bits - 1), 1<<(bits-1) - 1, true
+	default:
+		if bits == 64 {
+			return 0, math.MaxUint64, false
+		}
+		return 0, 1<<bits - 1, false
+	}
+}
+
+func overflowError(v interface{}, t reflect.Type) error {
+	return fmt.Errorf("Error converting %v to %s: %w", v, t.String(), ErrOverflow)
+}
+
+// Returns the bits of the signed value to store in the integer type t, checking the range
+// using the overflow mode.
+func intRangeBits(i int64, t reflect.Type, mode OverflowMode) (uint64, error) {
+	if mode == OVERFLOW_WRAP {
+		return uint64(i), nil
+	}
+	min, max, signed := intTypeRange(t)
+	if i < min {
+		if mode == OVERFLOW_SATURATE {
+			return uint64(min), nil
+		}
+		return 0, overflowError(i, t)
+	}
+	if (i > 0 || !signed) && uint64(i) > max {
+		if mode == OVERFLOW_SATURATE {
+			return max, nil
+		}
+		return 0, overflowError(i, t)
+	}
+	return uint64(i), nil
+}
+
+// Returns the bits of the unsigned value to store in the integer type t, checking the range
+// using the overflow mode.
+func uintRangeBits(u uint64, t reflect.Type, mode OverflowMode) (uint64, error) {
+	if mode == OVERFLOW_WRAP {
+		return u, nil
+	}
+	_, max, _ := intTypeRange(t)
+	if u > max {
+		if mode == OVERFLOW_SATURATE {
+			return max, nil
+		}
+		return 0, overflowError(u, t)
+	}
+	return u, nil
+}
+
+// Returns the bits of the integral float value to store in the integer type t, checking the range
+// using the overflow mode and the NaN policy.
+func floatRangeBits(f float64, t reflect.Type, mode OverflowMode, nan NaNPolicy) (uint64, error) {
+	min, max, signed := intTypeRange(t)
+	if mode == OVERFLOW_WRAP {
+		if signed {
+			return uint64(int64(f)), nil
+		}
+		return uint64(f), nil
+	}
+	if math.IsNaN(f) {
+		switch nan {
+		case NAN_ZERO:
+			return 0, nil
+		case NAN_MIN:
+			return uint64(min), nil
+		case NAN_MAX:
+			return max, nil
+		default:
+			return 0, fmt.Errorf("Error converting to %s: %w", t.String(), ErrNaN)
+		}
+	}
+	if f < float64(min) {
+		if mode == OVERFLOW_SATURATE {
+			return uint64(min), nil
+		}
+		return 0, overflowError(f, t)
+	}
+	// float64(max) rounds up to the next power of 2 for 64 bits, which is out of range
+	if f >= float64(max) && (f > float64(max) || max >= 1<<53) {
+		if mode == OVERFLOW_SATURATE {
+			return max, nil
+		}
+		return 0, overflowError(f, t)
+	}
+	if signed {
+		return uint64(int64(f)), nil
+	}
+	return uint64(f), nil
+}
@@ -0,0 +1,106 @@
+package rprim
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestOverflowSaturate(t *testing.T) {
+	c := NewConfig().SetOverflow(OVERFLOW_SATURATE)
+
+	tests := []struct {
+		value    interface{}
+		dst      interface{}
+		expected interface{}
+	}{
+		{300, uint8(0), uint8(255)},
+		{-5, uint(0), uint(0)},
+		{-200, int8(0), int8(-128)},
+		{uint64(math.MaxUint64), int64(0), int64(math.MaxInt64)},
+		{uint(70000), uint16(0), uint16(65535)},
+		{math.Inf(1), int64(0), int64(math.MaxInt64)},
+		{math.Inf(-1), int32(0), int32(math.MinInt32)},
+		{1e30, uint64(0), uint64(math.MaxUint64)},
+		{-3.5, uint8(0), uint8(0)},
+		{"300", uint8(0), uint8(255)},
+		{"-300", int8(0), int8(-128)},
+		{"-1", uint32(0), uint32(0)},
+		{"99999999999999999999", int64(0), int64(math.MaxInt64)},
+		{"99999999999999999999", uint64(0), uint64(math.MaxUint64)},
+		{100, int8(0), int8(100)},
+	}
+
+	for _, test := range tests {
+		cv, err := c.Convert(reflect.ValueOf(test.value), reflect.TypeOf(test.dst))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cv.Interface() != test.expected {
+			t.Fatalf("Converting %v to %T should be %v, is %v", test.value, test.dst, test.expected, cv.Interface())
+		}
+	}
+}
+
+func TestOverflowError(t *testing.T) {
+	c := NewConfig().SetOverflow(OVERFLOW_ERROR)
+
+	tests := []struct {
+		value interface{}
+		dst   interface{}
+	}{
+		{300, uint8(0)},
+		{-5, uint(0)},
+		{uint64(math.MaxUint64), int64(0)},
+		{math.Inf(1), int64(0)},
+		{float64(1 << 63), int64(0)},
+		{"300", uint8(0)},
+		{"-1", uint32(0)},
+		{"99999999999999999999", int64(0)},
+	}
+
+	for _, test := range tests {
+		_, err := c.Convert(reflect.ValueOf(test.value), reflect.TypeOf(test.dst))
+		if !errors.Is(err, ErrOverflow) {
+			t.Fatalf("Converting %v to %T should return overflow error, got %v", test.value, test.dst, err)
+		}
+	}
+
+	// default mode wraps
+	cv, err := Convert(reflect.ValueOf(300), reflect.TypeOf(uint8(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Uint() != 44 {
+		t.Fatalf("Value should be 44, is %d", cv.Uint())
+	}
+}
+
+func TestOverflowNaN(t *testing.T) {
+	nan := reflect.ValueOf(math.NaN())
+	dst := reflect.TypeOf(int16(0))
+
+	_, err := NewConfig().SetOverflow(OVERFLOW_SATURATE).Convert(nan, dst)
+	if !errors.Is(err, ErrNaN) {
+		t.Fatalf("Expected NaN error, got %v", err)
+	}
+
+	tests := []struct {
+		policy   NaNPolicy
+		expected int64
+	}{
+		{NAN_ZERO, 0},
+		{NAN_MIN, math.MinInt16},
+		{NAN_MAX, math.MaxInt16},
+	}
+	for _, test := range tests {
+		cv, err := NewConfig().SetOverflow(OVERFLOW_SATURATE).SetNaN(test.policy).Convert(nan, dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cv.Int() != test.expected {
+			t.Fatalf("NaN with policy %d should be %d, is %d", test.policy, test.expected, cv.Int())
+		}
+	}
+}
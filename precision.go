@@ -0,0 +1,82 @@
+package rprim
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// How to handle conversions to float that don't round-trip exactly.
+type PrecisionMode int
+
+const (
+	// Precision loss is ignored
+	PRECISION_IGNORE PrecisionMode = iota
+	// Precision loss returns an error
+	PRECISION_ERROR
+	// Precision loss calls the Config warning function, and the conversion continues
+	PRECISION_WARN
+)
+
+var (
+	// The value can't be represented exactly in the destination type
+	ErrPrecisionLoss = errors.New("Precision loss")
+	// The value is out of the range of the destination type
+	ErrOverflow = errors.New("Overflow")
+)
+
+// Returns the function that handles precision errors for the configured mode, or nil if they
+// should be ignored.
+func (c Config) precisionCheck() func(error) error {
+	switch c.Precision {
+	case PRECISION_ERROR:
+		return func(err error) error {
+			return err
+		}
+	case PRECISION_WARN:
+		warning := c.Warning
+		return func(err error) error {
+			if warning != nil {
+				warning(err)
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// Returns the float value converted to the float kind, and an error if it can't be represented exactly.
+func checkFloatPrecision(v float64, k reflect.Kind) (float64, error) {
+	if k != reflect.Float32 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return v, nil
+	}
+	f := float64(float32(v))
+	if math.IsInf(f, 0) {
+		return f, fmt.Errorf("Error converting %v to %s: %w", v, k.String(), ErrOverflow)
+	}
+	if f != v {
+		return f, fmt.Errorf("Error converting %v to %s: %w", v, k.String(), ErrPrecisionLoss)
+	}
+	return f, nil
+}
+
+// Returns an error if the int value can't be represented exactly in the float kind.
+func checkIntFloatPrecision(i int64, k reflect.Kind) error {
+	f, _ := checkFloatPrecision(float64(i), k)
+	// 2^63 is not representable as int64
+	if f >= math.MaxInt64 || int64(f) != i {
+		return fmt.Errorf("Error converting %d to %s: %w", i, k.String(), ErrPrecisionLoss)
+	}
+	return nil
+}
+
+// Returns an error if the uint value can't be represented exactly in the float kind.
+func checkUintFloatPrecision(u uint64, k reflect.Kind) error {
+	f, _ := checkFloatPrecision(float64(u), k)
+	// 2^64 is not representable as uint64
+	if f >= math.MaxUint64 || uint64(f) != u {
+		return fmt.Errorf("Error converting %d to %s: %w", u, k.String(), ErrPrecisionLoss)
+	}
+	return nil
+}
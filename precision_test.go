@@ -0,0 +1,94 @@
+package rprim
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestPrecisionIntFloat(t *testing.T) {
+	big := int64(1<<53 + 1)
+
+	// ignored by default
+	cv, err := Convert(reflect.ValueOf(big), reflect.TypeOf(float64(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(cv.Float()) == big {
+		t.Fatal("Value should have lost precision")
+	}
+
+	c := NewConfig().SetPrecision(PRECISION_ERROR)
+
+	_, err = c.Convert(reflect.ValueOf(big), reflect.TypeOf(float64(0)))
+	if !errors.Is(err, ErrPrecisionLoss) {
+		t.Fatalf("Expected precision loss error, got %v", err)
+	}
+
+	_, err = c.Convert(reflect.ValueOf(uint64(math.MaxUint64)), reflect.TypeOf(float64(0)))
+	if !errors.Is(err, ErrPrecisionLoss) {
+		t.Fatalf("Expected precision loss error, got %v", err)
+	}
+
+	_, err = c.Convert(reflect.ValueOf(int32(1<<24+1)), reflect.TypeOf(float32(0)))
+	if !errors.Is(err, ErrPrecisionLoss) {
+		t.Fatalf("Expected precision loss error, got %v", err)
+	}
+
+	cv, err = c.Convert(reflect.ValueOf(int64(1<<53)), reflect.TypeOf(float64(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Float() != 1<<53 {
+		t.Fatalf("Value should be %d, is %f", int64(1<<53), cv.Float())
+	}
+}
+
+func TestPrecisionFloatNarrowing(t *testing.T) {
+	c := NewConfig().SetPrecision(PRECISION_ERROR)
+
+	_, err := c.Convert(reflect.ValueOf(0.1), reflect.TypeOf(float32(0)))
+	if !errors.Is(err, ErrPrecisionLoss) {
+		t.Fatalf("Expected precision loss error, got %v", err)
+	}
+
+	_, err = c.Convert(reflect.ValueOf(1e300), reflect.TypeOf(float32(0)))
+	if !errors.Is(err, ErrOverflow) {
+		t.Fatalf("Expected overflow error, got %v", err)
+	}
+
+	_, err = c.Convert(reflect.ValueOf("1e300"), reflect.TypeOf(float32(0)))
+	if !errors.Is(err, ErrOverflow) {
+		t.Fatalf("Expected overflow error, got %v", err)
+	}
+
+	_, err = c.Convert(reflect.ValueOf(0.5), reflect.TypeOf(float32(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPrecisionWarning(t *testing.T) {
+	var warnings []error
+	c := NewConfig().SetPrecision(PRECISION_WARN).SetWarning(func(err error) {
+		warnings = append(warnings, err)
+	})
+
+	cv, err := c.Convert(reflect.ValueOf(0.1), reflect.TypeOf(float32(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Float() != float64(float32(0.1)) {
+		t.Fatalf("Invalid converted value %v", cv.Float())
+	}
+
+	_, err = c.Convert(reflect.ValueOf(0.5), reflect.TypeOf(float32(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(warnings) != 1 || !errors.Is(warnings[0], ErrPrecisionLoss) {
+		t.Fatalf("Expected one precision loss warning, got %v", warnings)
+	}
+}
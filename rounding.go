@@ -0,0 +1,47 @@
+package rprim
+
+import (
+	"fmt"
+	"math"
+)
+
+// Rounding mode used for float to integer conversions.
+type RoundingMode int
+
+const (
+	// Rounds toward zero
+	ROUND_TRUNCATE RoundingMode = iota
+	// Rounds toward negative infinity
+	ROUND_FLOOR
+	// Rounds toward positive infinity
+	ROUND_CEIL
+	// Rounds to the nearest integer, with halves away from zero
+	ROUND_HALF_UP
+	// Rounds to the nearest integer, with halves to the nearest even integer (banker's rounding)
+	ROUND_HALF_EVEN
+	// Returns an error if the value is not integral
+	ROUND_ERROR
+)
+
+// Rounds the float value to an integral value using the rounding mode.
+func roundFloat(v float64, mode RoundingMode) (float64, error) {
+	switch mode {
+	case ROUND_TRUNCATE:
+		return math.Trunc(v), nil
+	case ROUND_FLOOR:
+		return math.Floor(v), nil
+	case ROUND_CEIL:
+		return math.Ceil(v), nil
+	case ROUND_HALF_UP:
+		return math.Round(v), nil
+	case ROUND_HALF_EVEN:
+		return math.RoundToEven(v), nil
+	case ROUND_ERROR:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("Value %v is not integral", v)
+		}
+		return v, nil
+	default:
+		return 0, fmt.Errorf("Invalid rounding mode %d", int(mode))
+	}
+}
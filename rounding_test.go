@@ -0,0 +1,72 @@
+package rprim
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRounding(t *testing.T) {
+	tests := []struct {
+		rounding RoundingMode
+		value    float64
+		expected int64
+	}{
+		{ROUND_TRUNCATE, 2.7, 2},
+		{ROUND_TRUNCATE, -2.7, -2},
+		{ROUND_FLOOR, -2.5, -3},
+		{ROUND_CEIL, 2.1, 3},
+		{ROUND_HALF_UP, 2.5, 3},
+		{ROUND_HALF_UP, -2.5, -3},
+		{ROUND_HALF_EVEN, 2.5, 2},
+		{ROUND_HALF_EVEN, 3.5, 4},
+		{ROUND_ERROR, 4, 4},
+	}
+
+	for _, test := range tests {
+		cv, err := NewConfig().SetRounding(test.rounding).Convert(reflect.ValueOf(test.value), reflect.TypeOf(int64(0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cv.Int() != test.expected {
+			t.Fatalf("Rounding %d of %f should be %d, is %d", test.rounding, test.value, test.expected, cv.Int())
+		}
+	}
+
+	_, err := NewConfig().SetRounding(ROUND_ERROR).Convert(reflect.ValueOf(2.5), reflect.TypeOf(uint(0)))
+	if err == nil {
+		t.Fatal("Expected error converting non-integral value")
+	}
+}
+
+func TestRoundingLenientString(t *testing.T) {
+	_, err := Convert(reflect.ValueOf("2.5"), reflect.TypeOf(0))
+	if err == nil {
+		t.Fatal("Expected error converting fractional string to int")
+	}
+
+	c := NewConfig().AddFlags(COP_LENIENT_NUMERIC_STRING).SetRounding(ROUND_HALF_EVEN)
+
+	cv, err := c.Convert(reflect.ValueOf("2.5"), reflect.TypeOf(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Int() != 2 {
+		t.Fatalf("Value should be 2, is %d", cv.Int())
+	}
+
+	cv, err = c.Convert(reflect.ValueOf("3.5"), reflect.TypeOf(uint8(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Uint() != 4 {
+		t.Fatalf("Value should be 4, is %d", cv.Uint())
+	}
+
+	cv, err = c.Convert(reflect.ValueOf("-12"), reflect.TypeOf(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Int() != -12 {
+		t.Fatalf("Value should be -12, is %d", cv.Int())
+	}
+}
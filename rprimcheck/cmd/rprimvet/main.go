@@ -0,0 +1,18 @@
+/*
+Command rprimvet runs the rprimcheck analyzer, reporting rprim conversions that can never succeed.
+
+It is run by go vet:
+
+	go install github.com/RangelReale/rprim/rprimcheck/cmd/rprimvet
+	go vet -vettool=$(which rprimvet) ./...
+*/
+package main
+
+import (
+	"github.com/RangelReale/rprim/rprimcheck"
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+func main() {
+	unitchecker.Main(rprimcheck.Analyzer)
+}
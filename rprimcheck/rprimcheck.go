@@ -0,0 +1,448 @@
+/*
+Package rprimcheck defines an analyzer that reports rprim conversions that can never succeed.
+
+It inspects calls to rprim.Convert, ConvertOp, ConvertOpType, ConvertToString and the same
+rprim.Config methods. When the source and destination types are statically known, like in
+
+	rprim.Convert(reflect.ValueOf(x), reflect.TypeOf(0))
+
+it reports pairs that are never supported, pairs that are only supported with a COP_* flag that is not set,
+and pointer sources whose nil values return an error without COP_ALLOW_NIL_TO_ZERO_VALUE.
+
+The flags are known when they are constant, or when the Config is built in the same expression using
+rprim.NewConfig, SetFlags and AddFlags. If they are not known, only the never supported pairs are reported.
+
+It is a separate module, so the rprim package keeps no dependencies. It can be run with go vet using the
+cmd/rprimvet command:
+
+	go vet -vettool=$(which rprimvet) ./...
+*/
+package rprimcheck
+
+import (
+	"database/sql"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"math/big"
+	"reflect"
+
+	"github.com/RangelReale/rprim"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const rprimPath = "github.com/RangelReale/rprim"
+
+// Analyzer reports rprim conversions between statically known types that can never succeed.
+var Analyzer = &analysis.Analyzer{
+	Name:     "rprimcheck",
+	Doc:      "report rprim conversions between statically known types that can never succeed",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// All flags that change which conversions are supported.
+var allFlags = []uint{
+	rprim.COP_ALLOW_NIL_TO_ZERO_VALUE,
+	rprim.COP_ALLOW_STRING_TO_SLICE,
+	rprim.COP_ALLOW_SLICE_TO_SRING,
+	rprim.COP_LENIENT_NUMERIC_STRING,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	insp.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		fn := typeutil.StaticCallee(pass.TypesInfo, call)
+		if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != rprimPath {
+			return
+		}
+
+		var (
+			srcArg, dstArg ast.Expr
+			dstIsValue     bool
+			flags          uint
+			flagsKnown     bool
+		)
+
+		sig := fn.Type().(*types.Signature)
+		if sig.Recv() == nil {
+			// package functions use a config with only the passed flags
+			switch fn.Name() {
+			case "Convert":
+				if len(call.Args) != 2 {
+					return
+				}
+				srcArg, dstArg, flagsKnown = call.Args[0], call.Args[1], true
+			case "ConvertToString":
+				if len(call.Args) != 1 {
+					return
+				}
+				srcArg, flagsKnown = call.Args[0], true
+			case "ConvertOp", "ConvertOpType":
+				if len(call.Args) != 3 {
+					return
+				}
+				srcArg, dstArg, dstIsValue = call.Args[0], call.Args[1], fn.Name() == "ConvertOp"
+				flags, flagsKnown = constFlags(pass, call.Args[2])
+			default:
+				return
+			}
+		} else {
+			if !isConfig(sig.Recv().Type()) {
+				return
+			}
+			switch fn.Name() {
+			case "Convert", "ConvertOpType":
+				if len(call.Args) != 2 {
+					return
+				}
+				srcArg, dstArg = call.Args[0], call.Args[1]
+			case "ConvertOp":
+				if len(call.Args) != 2 {
+					return
+				}
+				srcArg, dstArg, dstIsValue = call.Args[0], call.Args[1], true
+			case "ConvertToString":
+				if len(call.Args) != 1 {
+					return
+				}
+				srcArg = call.Args[0]
+			default:
+				return
+			}
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+				flags, flagsKnown = configFlags(pass, sel.X)
+			}
+		}
+
+		srcType := valueOfType(pass, srcArg)
+		if srcType == nil {
+			return
+		}
+		var dstType types.Type
+		if dstArg == nil {
+			dstType = types.Typ[types.String]
+		} else if dstIsValue {
+			dstType = valueOfType(pass, dstArg)
+		} else {
+			dstType = typeOfType(pass, dstArg)
+		}
+		if dstType == nil {
+			return
+		}
+
+		src, ok := reflectType(srcType, map[types.Type]bool{})
+		if !ok {
+			return
+		}
+		dst, ok := reflectType(dstType, map[types.Type]bool{})
+		if !ok {
+			return
+		}
+
+		qualifier := types.RelativeTo(pass.Pkg)
+		srcName, dstName := types.TypeString(srcType, qualifier), types.TypeString(dstType, qualifier)
+		for _, msg := range checkConversion(src, dst, flags, flagsKnown) {
+			pass.Reportf(call.Pos(), msg, srcName, dstName)
+		}
+	})
+	return nil, nil
+}
+
+// Checks the conversion, returning the messages to report, which receive the source and
+// destination type names as arguments.
+func checkConversion(src, dst reflect.Type, flags uint, flagsKnown bool) []string {
+	var all uint
+	for _, f := range allFlags {
+		all |= f
+	}
+
+	e := rprim.NewConfig().SetFlags(all).Explain(src, dst)
+	switch {
+	case e.Strategy == rprim.STRATEGY_INVALID:
+		return []string{"rprim conversion from %s to %s is never supported"}
+	case e.Strategy == rprim.STRATEGY_DYNAMIC || !flagsKnown:
+		return nil
+	}
+
+	var ret []string
+	e = rprim.NewConfig().SetFlags(flags).Explain(src, dst)
+	if e.Strategy == rprim.STRATEGY_INVALID {
+		var needed uint
+		for _, f := range allFlags {
+			if flags&f == 0 && rprim.NewConfig().SetFlags(flags|f).Explain(src, dst).Strategy != rprim.STRATEGY_INVALID {
+				needed |= f
+			}
+		}
+		if needed == 0 {
+			needed = all &^ flags
+		}
+		ret = append(ret, "rprim conversion from %s to %s requires "+rprim.FlagsString(needed))
+	}
+	if e.NilStrategy == rprim.STRATEGY_ERROR {
+		ret = append(ret, "rprim conversion from nil %s to %s returns an error without "+rprim.FlagsString(e.NilFlags))
+	}
+	return ret
+}
+
+// Returns the constant flags value.
+func constFlags(pass *analysis.Pass, expr ast.Expr) (uint, bool) {
+	tv, ok := pass.TypesInfo.Types[expr]
+	if !ok || tv.Value == nil {
+		return 0, false
+	}
+	v, ok := constant.Uint64Val(constant.ToInt(tv.Value))
+	return uint(v), ok
+}
+
+// Returns the flags of a config built in the same expression, like rprim.NewConfig().AddFlags(...).
+func configFlags(pass *analysis.Pass, expr ast.Expr) (uint, bool) {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return 0, false
+	}
+	fn := typeutil.StaticCallee(pass.TypesInfo, call)
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != rprimPath {
+		return 0, false
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Recv() == nil {
+		return 0, fn.Name() == "NewConfig"
+	}
+
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return 0, false
+	}
+	flags, ok := configFlags(pass, sel.X)
+	if !ok {
+		return 0, false
+	}
+	switch fn.Name() {
+	case "SetFlags", "AddFlags":
+		f, ok := constFlags(pass, call.Args[0])
+		if !ok {
+			return 0, false
+		}
+		if fn.Name() == "SetFlags" {
+			return f, true
+		}
+		return flags | f, true
+	case "SetRounding", "SetPrecision", "SetOverflow", "SetNaN", "SetScale", "SetWarning", "Use", "Dup":
+		return flags, true
+	}
+	return 0, false
+}
+
+func isConfig(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == rprimPath && named.Obj().Name() == "Config"
+}
+
+// Returns the static type of x in reflect.ValueOf(x), or nil if not known.
+// Interface types are not known, as the conversion depends on the contents.
+func valueOfType(pass *analysis.Pass, expr ast.Expr) types.Type {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok || !isReflectFunc(pass, call, "ValueOf") || len(call.Args) != 1 {
+		return nil
+	}
+	return concreteType(pass.TypesInfo.TypeOf(call.Args[0]))
+}
+
+// Returns the type T in reflect.TypeOf(x) where x has type T, reflect.TypeOf((*T)(nil)).Elem() or
+// reflect.TypeFor[T](), or nil if not known.
+func typeOfType(pass *analysis.Pass, expr ast.Expr) types.Type {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+
+	// reflect.TypeOf((*T)(nil)).Elem()
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Elem" && len(call.Args) == 0 {
+		inner, ok := ast.Unparen(sel.X).(*ast.CallExpr)
+		if !ok || !isReflectFunc(pass, inner, "TypeOf") || len(inner.Args) != 1 {
+			return nil
+		}
+		if p, ok := pass.TypesInfo.TypeOf(inner.Args[0]).(*types.Pointer); ok {
+			return p.Elem()
+		}
+		return nil
+	}
+
+	if isReflectFunc(pass, call, "TypeFor") {
+		if inst, ok := pass.TypesInfo.Instances[typeForIdent(call.Fun)]; ok && inst.TypeArgs.Len() == 1 {
+			return inst.TypeArgs.At(0)
+		}
+		return nil
+	}
+
+	if !isReflectFunc(pass, call, "TypeOf") || len(call.Args) != 1 {
+		return nil
+	}
+	return concreteType(pass.TypesInfo.TypeOf(call.Args[0]))
+}
+
+func typeForIdent(expr ast.Expr) *ast.Ident {
+	switch e := expr.(type) {
+	case *ast.IndexExpr:
+		return typeForIdent(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel
+	case *ast.Ident:
+		return e
+	}
+	return nil
+}
+
+func concreteType(t types.Type) types.Type {
+	if t == nil || types.IsInterface(t) {
+		return nil
+	}
+	if b, ok := t.(*types.Basic); ok && b.Info()&types.IsUntyped != 0 {
+		if b.Kind() == types.UntypedNil {
+			return nil
+		}
+		return types.Default(t)
+	}
+	return t
+}
+
+func isReflectFunc(pass *analysis.Pass, call *ast.CallExpr, name string) bool {
+	fn := typeutil.StaticCallee(pass.TypesInfo, call)
+	return fn != nil && fn.Pkg() != nil && fn.Pkg().Path() == "reflect" && fn.Name() == name
+}
+
+// Types that are handled by name in rprim.
+var knownTypes = map[string]reflect.Type{
+	"math/big.Int":             reflect.TypeOf(big.Int{}),
+	"math/big.Float":           reflect.TypeOf(big.Float{}),
+	"math/big.Rat":             reflect.TypeOf(big.Rat{}),
+	"database/sql.NullBool":    reflect.TypeOf(sql.NullBool{}),
+	"database/sql.NullByte":    reflect.TypeOf(sql.NullByte{}),
+	"database/sql.NullFloat64": reflect.TypeOf(sql.NullFloat64{}),
+	"database/sql.NullInt16":   reflect.TypeOf(sql.NullInt16{}),
+	"database/sql.NullInt32":   reflect.TypeOf(sql.NullInt32{}),
+	"database/sql.NullInt64":   reflect.TypeOf(sql.NullInt64{}),
+	"database/sql.NullString":  reflect.TypeOf(sql.NullString{}),
+	"database/sql.NullTime":    reflect.TypeOf(sql.NullTime{}),
+}
+
+var basicTypes = map[types.BasicKind]reflect.Type{
+	types.Bool:       reflect.TypeOf(false),
+	types.Int:        reflect.TypeOf(int(0)),
+	types.Int8:       reflect.TypeOf(int8(0)),
+	types.Int16:      reflect.TypeOf(int16(0)),
+	types.Int32:      reflect.TypeOf(int32(0)),
+	types.Int64:      reflect.TypeOf(int64(0)),
+	types.Uint:       reflect.TypeOf(uint(0)),
+	types.Uint8:      reflect.TypeOf(uint8(0)),
+	types.Uint16:     reflect.TypeOf(uint16(0)),
+	types.Uint32:     reflect.TypeOf(uint32(0)),
+	types.Uint64:     reflect.TypeOf(uint64(0)),
+	types.Uintptr:    reflect.TypeOf(uintptr(0)),
+	types.Float32:    reflect.TypeOf(float32(0)),
+	types.Float64:    reflect.TypeOf(float64(0)),
+	types.Complex64:  reflect.TypeOf(complex64(0)),
+	types.Complex128: reflect.TypeOf(complex128(0)),
+	types.String:     reflect.TypeOf(""),
+}
+
+// Returns a reflect type with the same structure as the type, which is enough to select the conversion.
+// Named types are replaced by their underlying types, except the ones that rprim handles by name.
+// Returns false if the type can't be represented, like recursive types.
+func reflectType(t types.Type, seen map[types.Type]bool) (rt reflect.Type, ok bool) {
+	defer func() {
+		// reflect.StructOf panics on some unsupported fields
+		if recover() != nil {
+			rt, ok = nil, false
+		}
+	}()
+
+	switch tt := t.(type) {
+	case *types.Named:
+		obj := tt.Obj()
+		if obj.Pkg() != nil {
+			if known, ok := knownTypes[obj.Pkg().Path()+"."+obj.Name()]; ok {
+				return known, true
+			}
+			if obj.Pkg().Path() == "database/sql" || obj.Pkg().Path() == "math/big" {
+				return nil, false
+			}
+		}
+		if seen[tt] {
+			return nil, false
+		}
+		seen[tt] = true
+		defer delete(seen, tt)
+		return reflectType(tt.Underlying(), seen)
+	case *types.Alias:
+		return reflectType(types.Unalias(tt), seen)
+	case *types.Basic:
+		rt, ok := basicTypes[tt.Kind()]
+		return rt, ok
+	case *types.Pointer:
+		elem, ok := reflectType(tt.Elem(), seen)
+		if !ok {
+			return nil, false
+		}
+		return reflect.PointerTo(elem), true
+	case *types.Slice:
+		elem, ok := reflectType(tt.Elem(), seen)
+		if !ok {
+			return nil, false
+		}
+		return reflect.SliceOf(elem), true
+	case *types.Array:
+		elem, ok := reflectType(tt.Elem(), seen)
+		if !ok {
+			return nil, false
+		}
+		return reflect.ArrayOf(int(tt.Len()), elem), true
+	case *types.Map:
+		key, ok := reflectType(tt.Key(), seen)
+		if !ok {
+			return nil, false
+		}
+		elem, ok := reflectType(tt.Elem(), seen)
+		if !ok {
+			return nil, false
+		}
+		return reflect.MapOf(key, elem), true
+	case *types.Chan:
+		elem, ok := reflectType(tt.Elem(), seen)
+		if !ok {
+			return nil, false
+		}
+		return reflect.ChanOf(reflect.BothDir, elem), true
+	case *types.Interface:
+		return reflect.TypeOf((*interface{})(nil)).Elem(), true
+	case *types.Struct:
+		fields := make([]reflect.StructField, tt.NumFields())
+		for i := 0; i < tt.NumFields(); i++ {
+			f := tt.Field(i)
+			ft, ok := reflectType(f.Type(), seen)
+			if !ok {
+				return nil, false
+			}
+			fields[i] = reflect.StructField{
+				Name:      f.Name(),
+				Type:      ft,
+				Tag:       reflect.StructTag(tt.Tag(i)),
+				Anonymous: f.Embedded(),
+			}
+			if !f.Exported() {
+				fields[i].PkgPath = f.Pkg().Path()
+			}
+		}
+		return reflect.StructOf(fields), true
+	}
+	return nil, false
+}
@@ -0,0 +1,57 @@
+package rprimcheck
+
+import (
+	"go/types"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "a")
+}
+
+func TestReflectType(t *testing.T) {
+	pkg := types.NewPackage("example.com/p", "p")
+
+	// type Node struct { Value int; next *Node }
+	node := types.NewNamed(types.NewTypeName(0, pkg, "Node", nil), nil, nil)
+	node.SetUnderlying(types.NewStruct([]*types.Var{
+		types.NewField(0, pkg, "Value", types.Typ[types.Int], false),
+		types.NewField(0, pkg, "next", types.NewPointer(node), false),
+	}, nil))
+	if _, ok := reflectType(node, map[types.Type]bool{}); ok {
+		t.Fatal("Recursive types should not be represented")
+	}
+
+	// type Item struct { ID int64; name string }
+	item := types.NewStruct([]*types.Var{
+		types.NewField(0, pkg, "ID", types.Typ[types.Int64], false),
+		types.NewField(0, pkg, "name", types.Typ[types.String], false),
+	}, []string{`json:"id"`, ""})
+	rt, ok := reflectType(types.NewPointer(item), map[types.Type]bool{})
+	if !ok {
+		t.Fatal("Struct should be represented")
+	}
+	if rt.Kind() != reflect.Ptr || rt.Elem().NumField() != 2 || rt.Elem().Field(0).Tag.Get("json") != "id" {
+		t.Fatalf("Invalid type %s", rt.String())
+	}
+}
+
+func TestCheckConversion(t *testing.T) {
+	tInt, tString := reflect.TypeOf(0), reflect.TypeOf("")
+
+	if msgs := checkConversion(tInt, tString, 0, true); len(msgs) != 0 {
+		t.Fatalf("Expected no messages, got %v", msgs)
+	}
+	if msgs := checkConversion(reflect.TypeOf(struct{}{}), tInt, 0, false); len(msgs) != 1 {
+		t.Fatalf("Expected never supported message, got %v", msgs)
+	}
+	if msgs := checkConversion(reflect.PointerTo(tInt), tString, 0, true); len(msgs) != 1 {
+		t.Fatalf("Expected nil message, got %v", msgs)
+	}
+	if msgs := checkConversion(reflect.PointerTo(tInt), tString, 0, false); len(msgs) != 0 {
+		t.Fatalf("Expected no messages with unknown flags, got %v", msgs)
+	}
+}
@@ -0,0 +1,42 @@
+package a
+
+import (
+	"reflect"
+
+	"github.com/RangelReale/rprim"
+)
+
+type Item struct {
+	ID   int
+	Name string
+}
+
+type ID int64
+
+func conversions(x int, s string, p *int, item Item, c complex64, any interface{}, flags uint) {
+	rprim.Convert(reflect.ValueOf(x), reflect.TypeOf(""))
+	rprim.Convert(reflect.ValueOf(s), reflect.TypeOf(ID(0)))
+	rprim.Convert(reflect.ValueOf(item), reflect.TypeOf(0)) // want `rprim conversion from Item to int is never supported`
+	rprim.Convert(reflect.ValueOf(c), reflect.TypeOf(x))    // want `rprim conversion from complex64 to int is never supported`
+	rprim.Convert(reflect.ValueOf(any), reflect.TypeOf(0))
+	rprim.Convert(reflect.ValueOf(x), reflect.TypeOf(any))
+	rprim.Convert(reflect.ValueOf(s), reflect.TypeOf((*[]byte)(nil)).Elem()) // want `rprim conversion from string to \[\]byte requires COP_ALLOW_STRING_TO_SLICE`
+	rprim.Convert(reflect.ValueOf(p), reflect.TypeOf(0))                     // want `rprim conversion from nil \*int to int returns an error without COP_ALLOW_NIL_TO_ZERO_VALUE`
+	rprim.Convert(reflect.ValueOf(p), reflect.TypeOf(p))
+	rprim.ConvertToString(reflect.ValueOf(item)) // want `rprim conversion from Item to string is never supported`
+
+	rprim.ConvertOpType(reflect.ValueOf(s), reflect.TypeOf([]rune(nil)), rprim.COP_ALLOW_STRING_TO_SLICE)
+	rprim.ConvertOpType(reflect.ValueOf(s), reflect.TypeOf([]rune(nil)), 0) // want `requires COP_ALLOW_STRING_TO_SLICE`
+	rprim.ConvertOpType(reflect.ValueOf(s), reflect.TypeOf([]rune(nil)), flags)
+	rprim.ConvertOp(reflect.ValueOf(item), reflect.ValueOf(x), flags) // want `never supported`
+
+	rprim.NewConfig().AddFlags(rprim.COP_ALLOW_NIL_TO_ZERO_VALUE).Convert(reflect.ValueOf(p), reflect.TypeOf(0))
+	rprim.NewConfig().Dup().Convert(reflect.ValueOf(p), reflect.TypeOf(0)) // want `returns an error without COP_ALLOW_NIL_TO_ZERO_VALUE`
+	rprim.NewConfig().SetFlags(flags).Convert(reflect.ValueOf(p), reflect.TypeOf(0))
+	rprim.NewConfig().SetFlags(flags).Convert(reflect.ValueOf(c), reflect.TypeOf(0)) // want `never supported`
+
+	config := rprim.NewConfig()
+	config.Convert(reflect.ValueOf(p), reflect.TypeOf(0))
+	config.Convert(reflect.ValueOf([]string{}), reflect.TypeOf([]int{}))
+	config.Convert(reflect.ValueOf(map[string]Item{}), reflect.TypeOf(0)) // want `never supported`
+}
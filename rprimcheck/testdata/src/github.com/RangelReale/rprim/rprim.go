@@ -0,0 +1,43 @@
+// Package rprim is a stub with the signatures of the real package.
+package rprim
+
+import "reflect"
+
+const (
+	COP_ALLOW_NIL_TO_ZERO_VALUE = 1
+	COP_ALLOW_STRING_TO_SLICE   = 2
+	COP_ALLOW_SLICE_TO_SRING    = 4
+	COP_LENIENT_NUMERIC_STRING  = 8
+	COP_COLLECT_ERRORS          = 16
+	COP_ZERO_TO_NIL             = 32
+)
+
+type ConvertOpFunc func(reflect.Value, reflect.Type) (reflect.Value, error)
+
+type Config struct {
+	Flags uint
+}
+
+func NewConfig() *Config { return &Config{} }
+
+func (c *Config) SetFlags(flags uint) *Config { return c }
+func (c *Config) AddFlags(flags uint) *Config { return c }
+func (c Config) Dup() *Config                 { return &c }
+
+func (c Config) ConvertOp(src, dst reflect.Value) ConvertOpFunc                      { return nil }
+func (c Config) ConvertOpType(src reflect.Value, dstType reflect.Type) ConvertOpFunc { return nil }
+
+func (c *Config) Convert(src reflect.Value, dstType reflect.Type) (reflect.Value, error) {
+	return reflect.Value{}, nil
+}
+
+func (c *Config) ConvertToString(src reflect.Value) (string, error) { return "", nil }
+
+func ConvertOp(src, dst reflect.Value, flags uint) ConvertOpFunc                      { return nil }
+func ConvertOpType(src reflect.Value, dstType reflect.Type, flags uint) ConvertOpFunc { return nil }
+
+func Convert(src reflect.Value, dstType reflect.Type) (reflect.Value, error) {
+	return reflect.Value{}, nil
+}
+
+func ConvertToString(src reflect.Value) (string, error) { return "", nil }
@@ -0,0 +1,116 @@
+package rprim
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Parses a decimal string as an integer scaled by 10^scale, without going through float.
+// Returns an error if there are more fractional digits than the scale allows.
+func parseScaled(str string, scale int) (*big.Int, error) {
+	digits := str
+	neg := false
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		neg = digits[0] == '-'
+		digits = digits[1:]
+	}
+
+	intPart, fracPart := digits, ""
+	if pos := strings.IndexByte(digits, '.'); pos >= 0 {
+		intPart, fracPart = digits[:pos], digits[pos+1:]
+	}
+	if intPart == "" && fracPart == "" {
+		return nil, fmt.Errorf("Invalid decimal value '%s'", str)
+	}
+	for _, d := range intPart + fracPart {
+		if d < '0' || d > '9' {
+			return nil, fmt.Errorf("Invalid decimal value '%s'", str)
+		}
+	}
+	if len(fracPart) > scale {
+		return nil, fmt.Errorf("Decimal value '%s' has more than %d fractional digits", str, scale)
+	}
+
+	ret, _ := new(big.Int).SetString(intPart+fracPart+strings.Repeat("0", scale-len(fracPart)), 10)
+	if neg {
+		ret.Neg(ret)
+	}
+	return ret, nil
+}
+
+// Formats the absolute integer value as a decimal string with scale fractional digits.
+func formatScaled(neg bool, abs uint64, scale int) string {
+	digits := strconv.FormatUint(abs, 10)
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	ret := digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+	if neg {
+		ret = "-" + ret
+	}
+	return ret
+}
+
+// ConvertOp: decimal string -> [u]intXX scaled by 10^scale
+func cvtStringIntScaled(scale int, overflow OverflowMode) ConvertOpFunc {
+	// scaled values are always checked
+	if overflow == OVERFLOW_WRAP {
+		overflow = OVERFLOW_ERROR
+	}
+	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+		cv, err := parseScaled(UnderliningValue(v).String(), scale)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("Error converting string to int: %v", err)
+		}
+		bits, err := bigIntRangeBits(cv, UnderliningType(t), overflow)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return makeInt(bits, t)
+	}
+}
+
+// ConvertOp: intXX scaled by 10^scale -> decimal string
+func cvtIntStringScaled(scale int) ConvertOpFunc {
+	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+		i := UnderliningValue(v).Int()
+		if i < 0 {
+			return makeString(formatScaled(true, uint64(-i), scale), t)
+		}
+		return makeString(formatScaled(false, uint64(i), scale), t)
+	}
+}
+
+// ConvertOp: uintXX scaled by 10^scale -> decimal string
+func cvtUintStringScaled(scale int) ConvertOpFunc {
+	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+		return makeString(formatScaled(false, UnderliningValue(v).Uint(), scale), t)
+	}
+}
+
+// Returns the configuration to use for the struct field, applying the options of the "rprim" tag.
+// The supported option is "scale=N", which sets the fixed-point scale.
+// If the field has no options, returns the same configuration.
+func (c *Config) FieldConfig(field reflect.StructField) (*Config, error) {
+	tag, ok := field.Tag.Lookup("rprim")
+	if !ok {
+		return c, nil
+	}
+	ret := c
+	for _, opt := range strings.Split(tag, ",")[1:] {
+		if strings.HasPrefix(opt, "scale=") {
+			scale, err := strconv.Atoi(strings.TrimPrefix(opt, "scale="))
+			if err != nil || scale < 0 {
+				return nil, fmt.Errorf("Invalid scale option '%s' in field %s", opt, field.Name)
+			}
+			if ret == c {
+				ret = c.Dup()
+			}
+			ret.Scale = scale
+		}
+	}
+	return ret, nil
+}
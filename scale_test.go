@@ -0,0 +1,98 @@
+package rprim
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScaleParse(t *testing.T) {
+	c := NewConfig().SetScale(2)
+
+	tests := []struct {
+		value    string
+		expected int64
+	}{
+		{"12.34", 1234},
+		{"12.3", 1230},
+		{"12", 1200},
+		{"-0.05", -5},
+		{"+1.5", 150},
+		{".5", 50},
+	}
+	for _, test := range tests {
+		cv, err := c.Convert(reflect.ValueOf(test.value), reflect.TypeOf(int64(0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cv.Int() != test.expected {
+			t.Fatalf("Value '%s' should be %d, is %d", test.value, test.expected, cv.Int())
+		}
+	}
+
+	for _, value := range []string{"12.345", "abc", "1.2.3", "", "-"} {
+		if _, err := c.Convert(reflect.ValueOf(value), reflect.TypeOf(int64(0))); err == nil {
+			t.Fatalf("Expected error converting '%s'", value)
+		}
+	}
+
+	if _, err := c.Convert(reflect.ValueOf("-1.00"), reflect.TypeOf(uint(0))); err == nil {
+		t.Fatal("Expected error converting negative value to uint")
+	}
+	if _, err := c.Convert(reflect.ValueOf("1.28"), reflect.TypeOf(int8(0))); err == nil {
+		t.Fatal("Expected error converting out of range value to int8")
+	}
+}
+
+func TestScaleFormat(t *testing.T) {
+	c := NewConfig().SetScale(2)
+
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{int64(1234), "12.34"},
+		{-5, "-0.05"},
+		{uint(7), "0.07"},
+		{100, "1.00"},
+		{0, "0.00"},
+	}
+	for _, test := range tests {
+		str, err := c.ConvertToString(reflect.ValueOf(test.value))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if str != test.expected {
+			t.Fatalf("Value %v should be '%s', is '%s'", test.value, test.expected, str)
+		}
+	}
+}
+
+func TestFieldConfig(t *testing.T) {
+	type item struct {
+		Price int64 `rprim:",scale=3"`
+		Count int
+		Bad   int `rprim:",scale=x"`
+	}
+	it := reflect.TypeOf(item{})
+	c := NewConfig()
+
+	fc, err := c.FieldConfig(it.Field(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fc.Scale != 3 || c.Scale != 0 {
+		t.Fatalf("Invalid field scale %d", fc.Scale)
+	}
+
+	fc, err = c.FieldConfig(it.Field(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fc != c {
+		t.Fatal("Field without options should return the same configuration")
+	}
+
+	if _, err = c.FieldConfig(it.Field(2)); err == nil {
+		t.Fatal("Expected error with invalid scale")
+	}
+}
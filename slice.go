@@ -0,0 +1,220 @@
+package rprim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Optional parameters for slice conversion.
+type SliceOptions struct {
+	// Context used for cancellation, can be nil.
+	Context context.Context
+	// Number of goroutines to split the work into. Values lower than 2 convert on the calling goroutine.
+	Workers int
+	// Minimum number of items needed to use more than one worker.
+	MinParallel int
+	// Whether to keep converting after an error, returning all errors at the end.
+	// If not set, the error of the lowest failing index is returned, and items after it may not be converted.
+	CollectErrors bool
+}
+
+// Number of items converted between context cancellation checks.
+const sliceCheckInterval = 1024
+
+// Error of a single slice item conversion.
+type SliceItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *SliceItemError) Error() string {
+	return fmt.Sprintf("Error converting item %d: %v", e.Index, e.Err)
+}
+
+func (e *SliceItemError) Unwrap() error {
+	return e.Err
+}
+
+// List of slice item errors, sorted by index.
+type SliceErrors []*SliceItemError
+
+func (e SliceErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, ie := range e {
+		msgs[i] = ie.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e SliceErrors) Unwrap() []error {
+	ret := make([]error, len(e))
+	for i, ie := range e {
+		ret[i] = ie
+	}
+	return ret
+}
+
+// Converts all items of the src slice or array into the dst slice.
+// dst can be a slice with at least the same length as src, or a pointer to a slice, which will
+// be resized to the length of src if needed.
+// The converter is selected only once when the item types allows it.
+// The context error is only returned if the cancellation left items needed for the result unconverted.
+func (c *Config) ConvertSlice(dst, src interface{}, opts *SliceOptions) error {
+	if opts == nil {
+		opts = &SliceOptions{}
+	}
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	srcV := UnderliningValue(reflect.ValueOf(src))
+	if srcV.Kind() != reflect.Slice && srcV.Kind() != reflect.Array {
+		return fmt.Errorf("Source must be a slice or array, is %s", srcV.Kind().String())
+	}
+	n := srcV.Len()
+
+	dstV := reflect.ValueOf(dst)
+	if dstV.Kind() == reflect.Ptr {
+		if dstV.IsNil() {
+			return errors.New("Destination pointer is nil")
+		}
+		dstV = dstV.Elem()
+		if dstV.Kind() != reflect.Slice {
+			return fmt.Errorf("Destination must be a slice, is %s", dstV.Kind().String())
+		}
+		if dstV.Cap() < n {
+			dstV.Set(reflect.MakeSlice(dstV.Type(), n, n))
+		} else {
+			dstV.SetLen(n)
+		}
+	} else if dstV.Kind() != reflect.Slice {
+		return fmt.Errorf("Destination must be a slice or a pointer to slice, is %s", dstV.Kind().String())
+	} else if dstV.Len() < n {
+		return fmt.Errorf("Destination slice length %d is smaller than source length %d", dstV.Len(), n)
+	}
+
+	if n == 0 {
+		return nil
+	}
+
+	dstItemType := dstV.Type().Elem()
+
+	// nillable source items may select a different converter for each item
+	var cop ConvertOpFunc
+	srcItemKind := srcV.Type().Elem().Kind()
+	if srcItemKind != reflect.Ptr && srcItemKind != reflect.Interface {
+		cop = c.ConvertOpType(srcV.Index(0), dstItemType)
+		if cop == nil {
+			return fmt.Errorf("Invalid conversion from %s to %s", srcV.Type().Elem().String(), dstItemType.String())
+		}
+	}
+
+	convertItem := func(i int) error {
+		item := srcV.Index(i)
+		icop := cop
+		if icop == nil {
+			icop = c.ConvertOpType(item, dstItemType)
+			if icop == nil {
+				return fmt.Errorf("Invalid conversion from %s to %s", item.Type().String(), dstItemType.String())
+			}
+		}
+		cv, err := icop(item, dstItemType)
+		if err != nil {
+			return err
+		}
+		dstV.Index(i).Set(cv)
+		return nil
+	}
+
+	workers := opts.Workers
+	if workers > n {
+		workers = n
+	}
+	if workers < 2 || n < opts.MinParallel {
+		workers = 1
+	}
+
+	var (
+		errLock sync.Mutex
+		errs    SliceErrors
+		// lowest failing index, and lowest index not converted because of the context
+		firstErr = int64(n)
+		firstCut = int64(n)
+	)
+
+	addError := func(i int, err error) {
+		errLock.Lock()
+		errs = append(errs, &SliceItemError{Index: i, Err: err})
+		errLock.Unlock()
+		storeMinInt64(&firstErr, int64(i))
+	}
+
+	convertRange := func(start, end int) {
+		for i := start; i < end; i++ {
+			if (i-start)%sliceCheckInterval == 0 {
+				// items after a failed one don't change the result, but the ones before it do
+				if !opts.CollectErrors && int64(i) > atomic.LoadInt64(&firstErr) {
+					return
+				}
+				if ctx.Err() != nil {
+					storeMinInt64(&firstCut, int64(i))
+					return
+				}
+			}
+			if err := convertItem(i); err != nil {
+				addError(i, err)
+				if !opts.CollectErrors {
+					return
+				}
+			}
+		}
+	}
+
+	if workers == 1 {
+		convertRange(0, n)
+	} else {
+		var wg sync.WaitGroup
+		chunk := (n + workers - 1) / workers
+		for start := 0; start < n; start += chunk {
+			end := start + chunk
+			if end > n {
+				end = n
+			}
+			wg.Add(1)
+			go func(start, end int) {
+				defer wg.Done()
+				convertRange(start, end)
+			}(start, end)
+		}
+		wg.Wait()
+	}
+
+	if firstCut < int64(n) && (opts.CollectErrors || firstCut < firstErr) {
+		return ctx.Err()
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Index < errs[j].Index })
+	if !opts.CollectErrors {
+		return errs[0]
+	}
+	return errs
+}
+
+// Stores v in addr if it is lower than the current value.
+func storeMinInt64(addr *int64, v int64) {
+	for {
+		cur := atomic.LoadInt64(addr)
+		if v >= cur || atomic.CompareAndSwapInt64(addr, cur, v) {
+			return
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package rprim
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestConvertSlice(t *testing.T) {
+	src := []string{"1.5", "2", "3.25"}
+	dst := make([]float64, len(src))
+
+	err := ConvertSlice(dst, src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []float64{1.5, 2, 3.25}
+	for i := range expected {
+		if dst[i] != expected[i] {
+			t.Fatalf("Item %d should be %f, is %f", i, expected[i], dst[i])
+		}
+	}
+}
+
+func TestConvertSlicePointerDestination(t *testing.T) {
+	x := 10
+	src := []interface{}{1, "2", &x}
+	var dst []int
+
+	err := ConvertSlice(&dst, src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dst) != 3 || dst[0] != 1 || dst[1] != 2 || dst[2] != 10 {
+		t.Fatalf("Invalid converted slice: %v", dst)
+	}
+}
+
+func TestConvertSliceParallel(t *testing.T) {
+	src := make([]int, 10000)
+	for i := range src {
+		src[i] = i
+	}
+	var dst []string
+
+	err := ConvertSlice(&dst, src, &SliceOptions{Workers: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range src {
+		if dst[i] != strconv.Itoa(i) {
+			t.Fatalf("Item %d should be %d, is %s", i, i, dst[i])
+		}
+	}
+}
+
+func TestConvertSliceErrors(t *testing.T) {
+	src := []string{"1", "x", "3", "y"}
+	dst := make([]int, len(src))
+
+	// stop at first error
+	err := ConvertSlice(dst, src, nil)
+	var ierr *SliceItemError
+	if !errors.As(err, &ierr) || ierr.Index != 1 {
+		t.Fatalf("Expected error at index 1, got %v", err)
+	}
+
+	// collect all errors
+	err = ConvertSlice(dst, src, &SliceOptions{CollectErrors: true})
+	serr, ok := err.(SliceErrors)
+	if !ok {
+		t.Fatalf("Expected SliceErrors, got %v", err)
+	}
+	if len(serr) != 2 || serr[0].Index != 1 || serr[1].Index != 3 {
+		t.Fatalf("Invalid errors: %v", serr)
+	}
+	if dst[0] != 1 || dst[2] != 3 {
+		t.Fatalf("Valid items were not converted: %v", dst)
+	}
+}
+
+func TestConvertSliceParallelFirstError(t *testing.T) {
+	src := make([]string, 10000)
+	for i := range src {
+		src[i] = strconv.Itoa(i)
+	}
+	// the error of the last worker is found first
+	src[2499] = "x"
+	src[7500] = "y"
+	dst := make([]int, len(src))
+
+	err := ConvertSlice(dst, src, &SliceOptions{Workers: 4})
+	var ierr *SliceItemError
+	if !errors.As(err, &ierr) || ierr.Index != 2499 {
+		t.Fatalf("Expected error at index 2499, got %v", err)
+	}
+	for i := 0; i < 2499; i++ {
+		if dst[i] != i {
+			t.Fatalf("Item %d should be %d, is %d", i, i, dst[i])
+		}
+	}
+}
+
+func TestConvertSliceCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	src := []int{1, 2, 3}
+	dst := make([]string, len(src))
+
+	err := ConvertSlice(dst, src, &SliceOptions{Context: ctx})
+	if err != context.Canceled {
+		t.Fatalf("Expected context cancellation error, got %v", err)
+	}
+
+	// nothing left to convert
+	if err := ConvertSlice(dst[:0], src[:0], &SliceOptions{Context: ctx}); err != nil {
+		t.Fatal(err)
+	}
+
+	// canceled while converting the last item
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	c := NewConfig().Use(func(next ConvertOpFunc) ConvertOpFunc {
+		return func(v reflect.Value, typ reflect.Type) (reflect.Value, error) {
+			if v.Int() == 3 {
+				cancel()
+			}
+			return next(v, typ)
+		}
+	})
+	if err := c.ConvertSlice(dst, src, &SliceOptions{Context: ctx}); err != nil {
+		t.Fatal(err)
+	}
+	if dst[2] != "3" {
+		t.Fatalf("Item 2 should be 3, is %s", dst[2])
+	}
+}
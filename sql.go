@@ -0,0 +1,175 @@
+package rprim
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Checks if the type is one of the database/sql nullable wrappers (sql.NullString, sql.NullInt64, sql.Null[T], etc).
+// These are structs where the first field is the value and the second is the Valid flag.
+func IsSQLNullType(t reflect.Type) bool {
+	if t == nil || t.Kind() != reflect.Struct || t.PkgPath() != "database/sql" || !strings.HasPrefix(t.Name(), "Null") {
+		return false
+	}
+	return t.NumField() == 2 && t.Field(1).Name == "Valid" && t.Field(1).Type.Kind() == reflect.Bool
+}
+
+// Returns the conversion function to a sql nullable wrapper destination.
+func (c Config) sqlNullWrapOp(src convertSource, dstType reflect.Type) (ConvertOpFunc, ConvertStrategy, uint) {
+	if src.anyNil {
+		if dstType.Kind() == reflect.Ptr {
+			return cvtNil, STRATEGY_NIL, 0
+		}
+		// nil source is the invalid (null) value
+		return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+			root, _ := NewUnderliningValue(t)
+			return root, nil
+		}, STRATEGY_SQL_NULL_WRAP, 0
+	}
+
+	if src.utype == UnderliningType(dstType) {
+		return cvtDirectPointer, STRATEGY_DIRECT_POINTER, 0
+	}
+
+	valueType := UnderliningType(dstType).Field(0).Type
+	ok, flags := c.canConvertAssign(src, valueType)
+	if !ok {
+		return nil, STRATEGY_INVALID, flags
+	}
+
+	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+		root, last := NewUnderliningValue(t)
+		if UnderliningValueIsNil(v) {
+			return root, nil
+		}
+		cop := c.convertAssignOp(v, valueType)
+		if cop == nil {
+			return reflect.Value{}, fmt.Errorf("Invalid conversion from %s to %s", v.Type().String(), valueType.String())
+		}
+		cv, err := cop(v, valueType)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		last.Field(0).Set(cv)
+		last.Field(1).SetBool(true)
+		return root, nil
+	}, STRATEGY_SQL_NULL_WRAP, flags
+}
+
+// Returns the conversion function from a sql nullable wrapper source.
+// The invalid (null) value follows the same rules as a nil source value.
+func (c Config) sqlNullUnwrapOp(src convertSource, dstType reflect.Type) (ConvertOpFunc, ConvertStrategy, uint) {
+	valueType := src.utype.Field(0).Type
+	ok, flags := c.canConvertAssign(typeSource(valueType), dstType)
+	if !ok {
+		return nil, STRATEGY_INVALID, flags
+	}
+	if dstType.Kind() != reflect.Ptr && dstType.Kind() != reflect.Interface {
+		flags |= COP_ALLOW_NIL_TO_ZERO_VALUE
+	}
+
+	return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+		nv := UnderliningValue(v)
+		if !nv.Field(1).Bool() {
+			if t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface ||
+				(c.Flags&COP_ALLOW_NIL_TO_ZERO_VALUE) == COP_ALLOW_NIL_TO_ZERO_VALUE {
+				return reflect.Zero(t), nil
+			}
+			return reflect.Value{}, errors.New("Copying nil to zero value not allowed")
+		}
+		inner := nv.Field(0)
+		cop := c.convertAssignOp(inner, t)
+		if cop == nil {
+			return reflect.Value{}, fmt.Errorf("Invalid conversion from %s to %s", inner.Type().String(), t.String())
+		}
+		return cop(inner, t)
+	}, STRATEGY_SQL_NULL_UNWRAP, flags
+}
+
+// Returns the conversion function, falling back to direct assignment for non-primitive types
+// like bool and time.Time.
+func (c Config) convertAssignOp(src reflect.Value, dstType reflect.Type) ConvertOpFunc {
+	if cop, _, _ := c.convertOp(valueSource(src), dstType); cop != nil {
+		return cop
+	}
+	if UnderliningValueType(src).AssignableTo(UnderliningType(dstType)) {
+		return cvtDirectPointer
+	}
+	return nil
+}
+
+// Same as convertAssignOp, but only checks if the conversion is possible.
+func (c Config) canConvertAssign(src convertSource, dstType reflect.Type) (bool, uint) {
+	cop, _, flags := c.convertOp(src, dstType)
+	if cop != nil {
+		return true, flags
+	}
+	return src.utype.AssignableTo(UnderliningType(dstType)), flags
+}
+
+// Implements sql.Scanner for any destination pointer, converting the driver value using rprim.
+type Scanner struct {
+	Dest   interface{}
+	Config *Config
+}
+
+// Creates a new scanner for the destination pointer.
+func NewScanner(dest interface{}) *Scanner {
+	return NewConfig().Scanner(dest)
+}
+
+// Creates a new scanner for the destination pointer using this configuration.
+func (c *Config) Scanner(dest interface{}) *Scanner {
+	return &Scanner{
+		Dest:   dest,
+		Config: c,
+	}
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *Scanner) Scan(src interface{}) error {
+	dv := reflect.ValueOf(s.Dest)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return errors.New("Scan destination must be a non-nil pointer")
+	}
+	target := dv.Elem()
+	targetType := target.Type()
+	config := s.Config
+	if config == nil {
+		config = NewConfig()
+	}
+
+	var sv reflect.Value
+	switch st := src.(type) {
+	case nil:
+		// typed nil, so the nil rules of the conversion are used
+		sv = reflect.Zero(reflect.PtrTo(UnderliningType(targetType)))
+	case []byte:
+		// the driver may reuse the buffer, so it is always copied
+		sv = reflect.ValueOf(string(st))
+		if UnderliningTypeKind(targetType) == reflect.Slice {
+			config = config.Dup().AddFlags(COP_ALLOW_STRING_TO_SLICE)
+		}
+	case time.Time:
+		sv = reflect.ValueOf(st)
+		if UnderliningTypeKind(targetType) == reflect.String {
+			sv = reflect.ValueOf(st.Format(time.RFC3339Nano))
+		}
+	default:
+		sv = reflect.ValueOf(src)
+	}
+
+	cop := config.convertAssignOp(sv, targetType)
+	if cop == nil {
+		return fmt.Errorf("Invalid conversion from %s to %s", sv.Type().String(), targetType.String())
+	}
+	cv, err := cvtRecover(config.applyMiddlewares(cop))(sv, targetType)
+	if err != nil {
+		return err
+	}
+	target.Set(cv)
+	return nil
+}
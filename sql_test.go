@@ -0,0 +1,141 @@
+package rprim
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScanner(t *testing.T) {
+	var i int
+	if err := NewScanner(&i).Scan([]byte("15")); err != nil {
+		t.Fatal(err)
+	}
+	if i != 15 {
+		t.Fatalf("Value should be 15, is %d", i)
+	}
+
+	var f *float32
+	if err := NewScanner(&f).Scan("1.5"); err != nil {
+		t.Fatal(err)
+	}
+	if f == nil || *f != 1.5 {
+		t.Fatal("Value should be 1.5")
+	}
+
+	if err := NewScanner(&f).Scan(nil); err != nil {
+		t.Fatal(err)
+	}
+	if f != nil {
+		t.Fatal("Value should be nil")
+	}
+
+	var s string
+	if err := NewScanner(&s).Scan(int64(99)); err != nil {
+		t.Fatal(err)
+	}
+	if s != "99" {
+		t.Fatalf("Value should be 99, is %s", s)
+	}
+
+	var b bool
+	if err := NewScanner(&b).Scan(true); err != nil {
+		t.Fatal(err)
+	}
+	if !b {
+		t.Fatal("Value should be true")
+	}
+
+	tm := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	var ts time.Time
+	if err := NewScanner(&ts).Scan(tm); err != nil {
+		t.Fatal(err)
+	}
+	if !ts.Equal(tm) {
+		t.Fatalf("Value should be %v, is %v", tm, ts)
+	}
+
+	var bs []byte
+	buf := []byte("data")
+	if err := NewScanner(&bs).Scan(buf); err != nil {
+		t.Fatal(err)
+	}
+	buf[0] = 'x'
+	if string(bs) != "data" {
+		t.Fatalf("Value should be data, is %s", string(bs))
+	}
+}
+
+func TestScannerNilNotAllowed(t *testing.T) {
+	var i int
+	if err := NewScanner(&i).Scan(nil); err == nil {
+		t.Fatal("Expected error scanning nil into int")
+	}
+
+	if err := NewConfig().AddFlags(COP_ALLOW_NIL_TO_ZERO_VALUE).Scanner(&i).Scan(nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSQLNullWrap(t *testing.T) {
+	cv, err := Convert(reflect.ValueOf("12"), reflect.TypeOf(sql.NullInt64{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ni := cv.Interface().(sql.NullInt64)
+	if !ni.Valid || ni.Int64 != 12 {
+		t.Fatalf("Invalid value: %+v", ni)
+	}
+
+	var x *int
+	cv, err = Convert(reflect.ValueOf(x), reflect.TypeOf(sql.NullString{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns := cv.Interface().(sql.NullString)
+	if ns.Valid {
+		t.Fatalf("Value should not be valid: %+v", ns)
+	}
+
+	cv, err = Convert(reflect.ValueOf(true), reflect.TypeOf(&sql.NullBool{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb := cv.Interface().(*sql.NullBool)
+	if !nb.Valid || !nb.Bool {
+		t.Fatalf("Invalid value: %+v", nb)
+	}
+
+	cv, err = Convert(reflect.ValueOf(7), reflect.TypeOf(sql.Null[float32]{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ng := cv.Interface().(sql.Null[float32])
+	if !ng.Valid || ng.V != 7 {
+		t.Fatalf("Invalid value: %+v", ng)
+	}
+}
+
+func TestSQLNullUnwrap(t *testing.T) {
+	cv, err := Convert(reflect.ValueOf(sql.NullFloat64{Float64: 3, Valid: true}), reflect.TypeOf(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.String() != "3.000000" {
+		t.Fatalf("Value should be 3.000000, is %s", cv.String())
+	}
+
+	cv, err = Convert(reflect.ValueOf(sql.NullInt64{}), reflect.TypeOf((*int)(nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cv.IsNil() {
+		t.Fatal("Value should be nil")
+	}
+
+	_, err = Convert(reflect.ValueOf(sql.NullInt64{}), reflect.TypeOf(0))
+	if err == nil {
+		t.Fatal("Expected error converting null to int")
+	}
+}
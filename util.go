@@ -2,92 +2,208 @@ package rprim
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 )
 
-// Returns the underlining kind of the value, after all pointer and interface dereferences.
-func UnderliningValueKind(v reflect.Value) reflect.Kind {
-	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+// Default maximum number of pointer and interface indirections followed when walking values and types.
+// Deeper values return ErrIndirectionDepth, or ErrIndirectionCycle if the indirections loop.
+const DefaultMaxIndirectionDepth = 64
+
+var (
+	// The pointer or interface indirections loop
+	ErrIndirectionCycle = errors.New("Pointer indirection cycle")
+	// The pointer or interface indirections are deeper than the maximum depth
+	ErrIndirectionDepth = errors.New("Maximum pointer indirection depth exceeded")
+	// A pointer or interface inside the pointer chain is nil, only a nil source follows the nil rules
+	ErrNilPointerChain = errors.New("Nil inside pointer chain")
+)
+
+// Walks all value pointer and interface dereferences, stopping at nil.
+func walkValue(v reflect.Value, maxDepth int) (reflect.Value, bool, error) {
+	maxDepth = indirectionDepth(maxDepth)
+	for depth := 0; v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface; depth++ {
 		if v.IsNil() {
-			break
+			return v, true, nil
+		}
+		if depth >= maxDepth {
+			return v, false, valueIndirectionError(v, maxDepth)
 		}
 		v = v.Elem()
 	}
-	// if there is nil at any point, must walk using the type instead
-	vt := v.Type()
-	for vt.Kind() == reflect.Ptr {
-		vt = vt.Elem()
-	}
-	return vt.Kind()
+	return v, false, nil
 }
 
-// Returns the underlining type of the value, after all pointer and interface dereferences.
-func UnderliningValueType(v reflect.Value) reflect.Type {
-	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+// Checks whether the value indirections loop, only called when the depth limit is reached.
+func valueIndirectionError(v reflect.Value, maxDepth int) error {
+	type visit struct {
+		typ reflect.Type
+		ptr uintptr
+	}
+	seen := map[visit]bool{}
+	for depth := 0; depth < maxDepth && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface); depth++ {
 		if v.IsNil() {
 			break
 		}
+		if v.Kind() == reflect.Ptr {
+			key := visit{v.Type(), v.Pointer()}
+			if seen[key] {
+				return fmt.Errorf("Error walking %s: %w", v.Type().String(), ErrIndirectionCycle)
+			}
+			seen[key] = true
+		}
 		v = v.Elem()
 	}
-	// if there is nil at any point, must walk using the type instead
-	vt := v.Type()
-	for vt.Kind() == reflect.Ptr {
-		vt = vt.Elem()
+	return fmt.Errorf("Error walking %s: %w", v.Type().String(), ErrIndirectionDepth)
+}
+
+// Walks all type pointer dereferences.
+func walkType(t reflect.Type, maxDepth int) (reflect.Type, error) {
+	maxDepth = indirectionDepth(maxDepth)
+	for depth := 0; t.Kind() == reflect.Ptr; depth++ {
+		if depth >= maxDepth {
+			return t, typeIndirectionError(t, maxDepth)
+		}
+		t = t.Elem()
 	}
+	return t, nil
+}
+
+// Checks whether the type indirections loop, like type P *P, only called when the depth limit is reached.
+func typeIndirectionError(t reflect.Type, maxDepth int) error {
+	seen := map[reflect.Type]bool{}
+	for depth := 0; depth < maxDepth && t.Kind() == reflect.Ptr; depth++ {
+		if seen[t] {
+			return fmt.Errorf("Error walking %s: %w", t.String(), ErrIndirectionCycle)
+		}
+		seen[t] = true
+		t = t.Elem()
+	}
+	return fmt.Errorf("Error walking %s: %w", t.String(), ErrIndirectionDepth)
+}
+
+// Returns the maximum depth to walk, DefaultMaxIndirectionDepth if not positive.
+func indirectionDepth(maxDepth int) int {
+	if maxDepth <= 0 {
+		return DefaultMaxIndirectionDepth
+	}
+	return maxDepth
+}
+
+// Returns the underlining kind of the value, after all pointer and interface dereferences.
+// Stops after DefaultMaxIndirectionDepth indirections, use SafeUnderliningValueKind to get an error.
+func UnderliningValueKind(v reflect.Value) reflect.Kind {
+	k, _ := SafeUnderliningValueKind(v, 0)
+	return k
+}
+
+// Returns the underlining kind of the value, after all pointer and interface dereferences, or an error
+// if the indirections loop or are deeper than maxDepth (DefaultMaxIndirectionDepth if not positive).
+func SafeUnderliningValueKind(v reflect.Value, maxDepth int) (reflect.Kind, error) {
+	vt, err := SafeUnderliningValueType(v, maxDepth)
+	return vt.Kind(), err
+}
+
+// Returns the underlining type of the value, after all pointer and interface dereferences.
+// Stops after DefaultMaxIndirectionDepth indirections, use SafeUnderliningValueType to get an error.
+func UnderliningValueType(v reflect.Value) reflect.Type {
+	vt, _ := SafeUnderliningValueType(v, 0)
 	return vt
 }
 
+// Returns the underlining type of the value, after all pointer and interface dereferences, or an error
+// if the indirections loop or are deeper than maxDepth (DefaultMaxIndirectionDepth if not positive).
+func SafeUnderliningValueType(v reflect.Value, maxDepth int) (reflect.Type, error) {
+	v, isNil, err := walkValue(v, maxDepth)
+	if err != nil || !isNil {
+		return v.Type(), err
+	}
+	// if there is nil at any point, must walk using the type instead
+	return walkType(v.Type(), maxDepth)
+}
+
 // Returns the underlining value, after all pointer and interface dereferences.
+// Stops after DefaultMaxIndirectionDepth indirections, returning the value reached, use SafeUnderliningValue
+// to get an error.
 func UnderliningValue(v reflect.Value) reflect.Value {
-	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
-		if v.IsNil() {
-			break
-		}
-		v = v.Elem()
-	}
+	v, _, _ = walkValue(v, 0)
 	return v
 }
 
+// Returns the underlining value, after all pointer and interface dereferences, or an error if the
+// indirections loop or are deeper than maxDepth (DefaultMaxIndirectionDepth if not positive).
+func SafeUnderliningValue(v reflect.Value, maxDepth int) (reflect.Value, error) {
+	v, _, err := walkValue(v, maxDepth)
+	return v, err
+}
+
 // Returns if the underlining value is nil, even in any amount of pointer indirection
+// Values deeper than DefaultMaxIndirectionDepth are not nil, use SafeUnderliningValueIsNil to get an error.
 func UnderliningValueIsNil(v reflect.Value) bool {
-	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
-		if v.IsNil() {
-			return true
-		}
-		v = v.Elem()
-	}
-	return false
+	_, isNil, _ := walkValue(v, 0)
+	return isNil
 }
 
-// Returns the underlining kind of the type, after all pointer and interface dereferences.
+// Returns if the underlining value is nil, even in any amount of pointer indirection, or an error if the
+// indirections loop or are deeper than maxDepth (DefaultMaxIndirectionDepth if not positive).
+func SafeUnderliningValueIsNil(v reflect.Value, maxDepth int) (bool, error) {
+	_, isNil, err := walkValue(v, maxDepth)
+	return isNil, err
+}
+
+// Returns the underlining kind of the type, after all pointer dereferences. Interface contents are only
+// known at runtime, so types like *any and **fmt.Stringer return reflect.Interface.
+// Stops after DefaultMaxIndirectionDepth indirections, use SafeUnderliningTypeKind to get an error.
 func UnderliningTypeKind(v reflect.Type) reflect.Kind {
+	k, _ := SafeUnderliningTypeKind(v, 0)
+	return k
+}
+
+// Returns the underlining kind of the type, after all pointer dereferences, or an error if the
+// indirections loop or are deeper than maxDepth (DefaultMaxIndirectionDepth if not positive).
+// As in UnderliningTypeKind, interfaces are not dereferenced.
+func SafeUnderliningTypeKind(v reflect.Type, maxDepth int) (reflect.Kind, error) {
 	if v == nil {
-		return reflect.Interface
+		return reflect.Interface, nil
 	}
-	for v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	return v.Kind()
+	v, err := walkType(v, maxDepth)
+	return v.Kind(), err
 }
 
-// Returns the underlining type, after all pointer and interface dereferences.
+// Returns the underlining type, after all pointer dereferences. Interface contents are only known at
+// runtime, so types like *any and **fmt.Stringer return the interface type.
+// Stops after DefaultMaxIndirectionDepth indirections, use SafeUnderliningType to get an error.
 func UnderliningType(v reflect.Type) reflect.Type {
+	v, _ = SafeUnderliningType(v, 0)
+	return v
+}
+
+// Returns the underlining type, after all pointer dereferences, or an error if the indirections
+// loop or are deeper than maxDepth (DefaultMaxIndirectionDepth if not positive).
+// As in UnderliningType, interfaces are not dereferenced.
+func SafeUnderliningType(v reflect.Type, maxDepth int) (reflect.Type, error) {
 	if v == nil {
-		return nil
+		return nil, nil
 	}
-	for v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	return v
+	return walkType(v, maxDepth)
 }
 
 // Creates a new instance of the type, returning the root item, and the last if the type contains any
 // pointer or interface, else returns the same as root.
 // The last item is always de zero-value of the type (nil if value is nillable).
+// Stops after DefaultMaxIndirectionDepth indirections, use SafeNewUnderliningValue to get an error.
 func NewUnderliningValue(v reflect.Type) (root reflect.Value, last reflect.Value) {
+	root, last, _ = SafeNewUnderliningValue(v, 0)
+	return
+}
+
+// Same as NewUnderliningValue, but returns an error if the type indirections loop or are deeper than
+// maxDepth (DefaultMaxIndirectionDepth if not positive).
+func SafeNewUnderliningValue(v reflect.Type, maxDepth int) (root reflect.Value, last reflect.Value, err error) {
+	maxDepth = indirectionDepth(maxDepth)
 	root = reflect.Value{}
 	last = reflect.Value{}
-	for {
+	for depth := 0; ; depth++ {
 		newi := reflect.New(v)
 		if !root.IsValid() {
 			root = newi.Elem()
@@ -95,21 +211,30 @@ func NewUnderliningValue(v reflect.Type) (root reflect.Value, last reflect.Value
 			last.Set(newi)
 		}
 		last = newi.Elem()
-		if v.Kind() == reflect.Ptr {
-			v = v.Elem()
-		} else {
+		if v.Kind() != reflect.Ptr {
 			break
 		}
+		if depth >= maxDepth {
+			err = typeIndirectionError(v, maxDepth)
+			break
+		}
+		v = v.Elem()
 	}
 
 	return
 }
 
 // Ensure that all the passed value pointer indirections are not nil, and returns the last
-// non-pointer value.
+// non-pointer value. Returns an error after DefaultMaxIndirectionDepth indirections.
 func EnsureUnderliningValue(v reflect.Value) (last reflect.Value, err error) {
 	cur := v
-	for cur.Kind() == reflect.Ptr {
+	for depth := 0; cur.Kind() == reflect.Ptr; depth++ {
+		if depth >= DefaultMaxIndirectionDepth {
+			if cur.IsNil() {
+				return reflect.Value{}, typeIndirectionError(cur.Type(), DefaultMaxIndirectionDepth)
+			}
+			return reflect.Value{}, valueIndirectionError(cur, DefaultMaxIndirectionDepth)
+		}
 		if cur.IsNil() {
 			if cur.CanSet() {
 				cur.Set(reflect.New(cur.Type().Elem()))
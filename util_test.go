@@ -1,6 +1,7 @@
 package rprim
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -127,3 +128,82 @@ func TestEnsureUnderliningValueInterface(t *testing.T) {
 		t.Fatal("Pointed value was not set correctly")
 	}
 }
+
+type testRecursivePtr *testRecursivePtr
+
+func TestUnderliningRecursiveType(t *testing.T) {
+	rt := reflect.TypeOf(testRecursivePtr(nil))
+
+	if _, err := SafeUnderliningType(rt, 0); !errors.Is(err, ErrIndirectionCycle) {
+		t.Fatalf("Expected cycle error, got %v", err)
+	}
+	if _, err := SafeUnderliningTypeKind(rt, 0); !errors.Is(err, ErrIndirectionCycle) {
+		t.Fatalf("Expected cycle error, got %v", err)
+	}
+	if k := UnderliningTypeKind(rt); k != reflect.Ptr {
+		t.Fatalf("Kind should be ptr, is %s", k.String())
+	}
+	if _, err := SafeUnderliningValueType(reflect.ValueOf(testRecursivePtr(nil)), 0); !errors.Is(err, ErrIndirectionCycle) {
+		t.Fatalf("Expected cycle error, got %v", err)
+	}
+	if _, _, err := SafeNewUnderliningValue(rt, 0); !errors.Is(err, ErrIndirectionCycle) {
+		t.Fatalf("Expected cycle error, got %v", err)
+	}
+	if root, _ := NewUnderliningValue(rt); !root.IsValid() {
+		t.Fatal("Root value should be valid")
+	}
+
+	var p testRecursivePtr
+	if _, err := EnsureUnderliningValue(reflect.ValueOf(&p)); !errors.Is(err, ErrIndirectionCycle) {
+		t.Fatalf("Expected cycle error, got %v", err)
+	}
+}
+
+func TestUnderliningRecursiveValue(t *testing.T) {
+	var x interface{}
+	x = &x
+	v := reflect.ValueOf(x)
+
+	if _, err := SafeUnderliningValue(v, 0); !errors.Is(err, ErrIndirectionCycle) {
+		t.Fatalf("Expected cycle error, got %v", err)
+	}
+	if _, err := SafeUnderliningValueIsNil(v, 0); !errors.Is(err, ErrIndirectionCycle) {
+		t.Fatalf("Expected cycle error, got %v", err)
+	}
+	if _, err := SafeUnderliningValueKind(v, 0); !errors.Is(err, ErrIndirectionCycle) {
+		t.Fatalf("Expected cycle error, got %v", err)
+	}
+	if UnderliningValueIsNil(v) {
+		t.Fatal("Value should not be nil")
+	}
+	if k := UnderliningValueKind(v); k != reflect.Ptr && k != reflect.Interface {
+		t.Fatalf("Kind should be ptr or interface, is %s", k.String())
+	}
+
+	if _, err := Convert(v, reflect.TypeOf("")); !errors.Is(err, ErrIndirectionCycle) {
+		t.Fatalf("Expected cycle error, got %v", err)
+	}
+	if _, err := ConvertToString(v); !errors.Is(err, ErrIndirectionCycle) {
+		t.Fatalf("Expected cycle error, got %v", err)
+	}
+}
+
+func TestUnderliningMaxDepth(t *testing.T) {
+	var i ***int
+	if _, err := SafeUnderliningType(reflect.TypeOf(i), 2); !errors.Is(err, ErrIndirectionDepth) {
+		t.Fatalf("Expected depth error, got %v", err)
+	}
+	if _, err := SafeUnderliningValueType(reflect.ValueOf(i), 2); !errors.Is(err, ErrIndirectionDepth) {
+		t.Fatalf("Expected depth error, got %v", err)
+	}
+
+	v := 1
+	pv := &v
+	ppv := &pv
+	if _, err := SafeUnderliningValue(reflect.ValueOf(&ppv), 2); !errors.Is(err, ErrIndirectionDepth) {
+		t.Fatalf("Expected depth error, got %v", err)
+	}
+	if uv, err := SafeUnderliningValue(reflect.ValueOf(ppv), 2); err != nil || uv.Int() != 1 {
+		t.Fatalf("Expected 1, got %v (%v)", uv, err)
+	}
+}
@@ -0,0 +1,70 @@
+package rprim
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Returns the index of the value field if the type is a single-field wrapper struct, or -1.
+// The value field is the exported field tagged with `rprim:",value"`, or the only exported field
+// if it is a primitive value.
+func WrapperField(t reflect.Type) int {
+	if t == nil || t.Kind() != reflect.Struct || IsSQLNullType(t) || IsBigType(t) {
+		return -1
+	}
+	field := -1
+	exported := 0
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		if tag, ok := f.Tag.Lookup("rprim"); ok {
+			for _, opt := range strings.Split(tag, ",")[1:] {
+				if opt == "value" {
+					return i
+				}
+			}
+		}
+		exported++
+		field = i
+	}
+	if exported != 1 || !KindIsSimpleValue(UnderliningTypeKind(t.Field(field).Type)) {
+		return -1
+	}
+	return field
+}
+
+// Returns the conversion function from or to single-field wrapper structs, or nil if not supported.
+func (c Config) wrapperOp(srcType, dstType reflect.Type) ConvertOpFunc {
+	srcField, dstField := WrapperField(srcType), WrapperField(dstType)
+
+	// source is unwrapped first
+	if srcField >= 0 {
+		if !c.canConvertElem(srcType.Field(srcField).Type, dstType) {
+			return nil
+		}
+		return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+			return c.convertElem(UnderliningValue(v).Field(srcField), t)
+		}
+	}
+
+	if dstField >= 0 {
+		fieldType := dstType.Field(dstField).Type
+		if !c.canConvertElem(srcType, fieldType) {
+			return nil
+		}
+		return func(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+			root, last := NewUnderliningValue(t)
+			cv, err := c.convertElem(v, fieldType)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			last.Field(dstField).Set(cv)
+			return root, nil
+		}
+	}
+
+	return nil
+}
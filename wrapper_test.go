@@ -0,0 +1,95 @@
+package rprim
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testUserID struct {
+	V int64
+}
+
+type testTagged struct {
+	Value string `rprim:",value"`
+	Note  string
+}
+
+type testOrderID struct {
+	ID uint16
+}
+
+func TestWrapperField(t *testing.T) {
+	if WrapperField(reflect.TypeOf(testUserID{})) != 0 {
+		t.Fatal("testUserID should be a wrapper")
+	}
+	if WrapperField(reflect.TypeOf(testTagged{})) != 0 {
+		t.Fatal("testTagged should be a wrapper")
+	}
+	if WrapperField(reflect.TypeOf(struct{ A, B int }{})) != -1 {
+		t.Fatal("Struct with two fields should not be a wrapper")
+	}
+	if WrapperField(reflect.TypeOf(struct{ A []int }{})) != -1 {
+		t.Fatal("Struct with non-primitive field should not be a wrapper")
+	}
+}
+
+func TestWrapperConvert(t *testing.T) {
+	cv, err := Convert(reflect.ValueOf("42"), reflect.TypeOf(testUserID{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Interface().(testUserID).V != 42 {
+		t.Fatalf("Invalid value %v", cv.Interface())
+	}
+
+	var pp **testUserID
+	cv, err = Convert(reflect.ValueOf(7), reflect.TypeOf(pp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pp = cv.Interface().(**testUserID)
+	if (**pp).V != 7 {
+		t.Fatalf("Invalid value %v", (**pp).V)
+	}
+
+	str, err := ConvertToString(reflect.ValueOf(&testUserID{V: 99}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if str != "99" {
+		t.Fatalf("Value should be 99, is %s", str)
+	}
+
+	cv, err = Convert(reflect.ValueOf(testTagged{Value: "12", Note: "x"}), reflect.TypeOf(float32(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Float() != 12 {
+		t.Fatalf("Value should be 12, is %f", cv.Float())
+	}
+
+	// wrapper to wrapper with different field names
+	cv, err = Convert(reflect.ValueOf(testUserID{V: 5}), reflect.TypeOf(testOrderID{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Interface().(testOrderID).ID != 5 {
+		t.Fatalf("Invalid value %v", cv.Interface())
+	}
+
+	// wrapper to non-wrapper struct is converted field by field
+	cv, err = Convert(reflect.ValueOf(testUserID{V: 5}), reflect.TypeOf(struct {
+		V string
+		X int
+	}{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Field(0).String() != "5" {
+		t.Fatalf("Invalid value %v", cv.Interface())
+	}
+
+	if CanConvert(reflect.TypeOf(testUserID{}), reflect.TypeOf(complex64(0))) {
+		t.Fatal("testUserID should not be convertible to complex64")
+	}
+}